@@ -11,8 +11,12 @@ import (
 	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
 	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
 	"github.com/Jordanzuo/ChatServer/src/config"
+	"github.com/Jordanzuo/ChatServer/src/metric"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/ChatServer/src/rpcClient"
 	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
 	"github.com/Jordanzuo/goutil/debugUtil"
 	"github.com/Jordanzuo/goutil/logUtil"
 	"os"
@@ -23,6 +27,14 @@ import (
 	"time"
 )
 
+const (
+	// 关闭服务器时等待与ChatServerCenter的连接断开的最长时间
+	con_ShutdownCenterTimeout = 5 * time.Second
+
+	// 关闭服务器时等待客户端数据发送完成的最长时间
+	con_ShutdownDrainTimeout = 10 * time.Second
+)
+
 var (
 	wg sync.WaitGroup
 )
@@ -62,6 +74,7 @@ func signalProc() {
 			logUtil.Log("收到退出程序的信号，开始退出……", logUtil.Info, true)
 
 			// 做一些收尾的工作
+			shutdown()
 
 			logUtil.Log("收到退出程序的信号，退出完成……", logUtil.Info, true)
 
@@ -71,6 +84,28 @@ func signalProc() {
 	}
 }
 
+// 关闭服务器：停止接受新连接，通知ChatServerCenter，通知所有客户端，等待数据发送完成，然后断开所有连接
+func shutdown() {
+	// 停止接受新的连接
+	rpcServer.StopServer()
+
+	// 通知ChatServerCenter本服务器即将下线
+	if !rpcClient.Logout(con_ShutdownCenterTimeout) {
+		logUtil.Log("通知ChatServerCenter下线失败或超时", logUtil.Warn, true)
+	}
+
+	// 通知所有客户端服务器维护中
+	responseObj := serverResponseObject.NewResponseObject(commandType.Login)
+	responseObj.SetResultStatus(model.Con_ServerMaintenance)
+	rpcServer.ResponseResultToAllClients(responseObj, rpcServer.Con_HighPriority)
+
+	// 等待待发送的数据发送完成
+	rpcServer.WaitForSendDataDrained(con_ShutdownDrainTimeout)
+
+	// 断开所有的客户端连接
+	rpcServer.DisconnectAllClients()
+}
+
 // 记录当前运行的Goroutine数量
 func recordGoroutineNum() {
 	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
@@ -95,6 +130,11 @@ func main() {
 	// 记录当前运行的Goroutine数量
 	go recordGoroutineNum()
 
+	// 如果配置了指标服务器监听地址，则启动指标服务器
+	if config.MetricsListenAddress != "" {
+		go metric.StartServer(config.MetricsListenAddress)
+	}
+
 	// 获取数据库配置
 	configObj := configBLL.GetConfig()
 
@@ -109,16 +149,31 @@ func main() {
 
 	// 设置rpcServer配置，并启动服务器
 	rpcServer.SetConfig(config.ChatServerListenAddress,
+		config.WebSocketListenAddress,
 		playerBLL.GetPlayer,
 		playerBLL.GetPlayerCount,
 		playerBLL.DisconnectByClient,
-		chatBLL.Login,
-		chatBLL.Logout,
-		chatBLL.UpdatePlayerInfo,
-		chatBLL.SendMessage,
+		config.MaxSendQueueLength,
+		config.SendQueueHighWaterMark,
+		time.Duration(config.SlowClientTimeout)*time.Second,
 		config.DEBUG)
+
+	// 设置TLS配置，并注册证书的重新加载方法（收到SIGHUP信号时重新加载证书）
+	if err := rpcServer.SetTLSConfig(config.TLSCertFile, config.TLSKeyFile, config.TLSMinVersion, config.TLSClientCAFile); err != nil {
+		panic(err)
+	}
+	reloadBLL.RegisterReloadFunc("TLS", rpcServer.ReloadTLSCertificate)
+
+	// 设置WebSocket允许的来源
+	rpcServer.SetWebSocketConfig(config.WebSocketAllowedOrigins)
+
 	go rpcServer.StartServer(&wg)
 
+	// 如果配置了WebSocket监听地址，则同时启动WebSocket服务器
+	if config.WebSocketListenAddress != "" {
+		go rpcServer.StartWebSocketServer(&wg)
+	}
+
 	// 阻塞等待，以免main线程退出
 	wg.Wait()
 }
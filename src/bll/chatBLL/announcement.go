@@ -0,0 +1,136 @@
+package chatBLL
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseData"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 检查系统公告是否需要推送的间隔
+	con_AnnouncementCheckInterval = time.Second
+)
+
+var (
+	// 每个系统公告最近一次推送的时间（每个ChatServer只推送给自己的玩家，故各自记录），及其锁对象
+	announcementSendTimeMap   = make(map[int]time.Time, 8)
+	announcementSendTimeMutex sync.Mutex
+)
+
+func init() {
+	go scheduleAnnouncement()
+}
+
+// 定时推送系统公告
+func scheduleAnnouncement() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		time.Sleep(con_AnnouncementCheckInterval)
+
+		for _, item := range getDueAnnouncementList(time.Now()) {
+			sendAnnouncement(item)
+		}
+	}
+}
+
+// 获取需要推送的系统公告列表，并记录推送时间
+// now：当前时间
+// 返回值：
+// 需要推送的系统公告列表（按优先级从高到低）
+func getDueAnnouncementList(now time.Time) []*model.Announcement {
+	announcementList := configBLL.GetAnnouncementList()
+	dueList := make([]*model.Announcement, 0, len(announcementList))
+
+	announcementSendTimeMutex.Lock()
+	defer announcementSendTimeMutex.Unlock()
+
+	existsIdMap := make(map[int]bool, len(announcementList))
+	for _, item := range announcementList {
+		existsIdMap[item.Id] = true
+		if !item.IfActive(now) {
+			continue
+		}
+
+		// 未推送过的立即推送；重复推送的公告在间隔到达后再次推送
+		lastSendTime, sent := announcementSendTimeMap[item.Id]
+		if sent && (!item.IfRepeat() || now.Sub(lastSendTime) < item.GetRepeatInterval()) {
+			continue
+		}
+
+		announcementSendTimeMap[item.Id] = now
+		dueList = append(dueList, item)
+	}
+
+	// 移除已删除、已结束的公告的记录
+	for id := range announcementSendTimeMap {
+		if !existsIdMap[id] {
+			delete(announcementSendTimeMap, id)
+		}
+	}
+
+	return dueList
+}
+
+// 将系统公告推送给本服务器在线的目标玩家
+// announcementObj：系统公告
+func sendAnnouncement(announcementObj *model.Announcement) {
+	var finalPlayerList []*player.Player
+	if serverGroupIdList, _ := announcementObj.GetServerGroupIdList(); len(serverGroupIdList) == 0 {
+		finalPlayerList = playerBLL.GetAllPlayerList()
+	} else {
+		for _, serverGroupId := range serverGroupIdList {
+			finalPlayerList = append(finalPlayerList, playerBLL.GetPlayerListInServerGroup(serverGroupId)...)
+		}
+	}
+
+	if len(finalPlayerList) == 0 {
+		return
+	}
+
+	// 记录指标
+	sentMessageCounter.Add(channelTypeLabel(model.Con_Channel_Announcement), float64(len(finalPlayerList)))
+
+	playerBLL.SendToPlayer(finalPlayerList, newAnnouncementResponseObject(announcementObj))
+}
+
+// 登陆成功后发送有效期内的系统公告
+// clientObj：客户端对象
+// playerObj：玩家对象
+func sendAnnouncementOnLogin(clientObj *rpcServer.Client, playerObj *player.Player) {
+	now := time.Now()
+	for _, item := range configBLL.GetAnnouncementList() {
+		if item.IfActive(now) && item.IfTargetServerGroup(playerObj.ServerGroupId) {
+			playerBLL.SendToClient(clientObj, newAnnouncementResponseObject(item))
+		}
+	}
+}
+
+// 新建系统公告的响应对象
+// announcementObj：系统公告
+// 返回值：
+// 响应对象
+func newAnnouncementResponseObject(announcementObj *model.Announcement) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(commandType.SendMessage)
+	responseObj.SetData(&announcementResponseData{
+		ResponseData:   serverResponseData.NewResponseData(model.Con_Channel_Announcement, announcementObj.Message, nil, nil),
+		AnnouncementId: announcementObj.Id,
+		Priority:       announcementObj.Priority,
+	})
+
+	return responseObj
+}
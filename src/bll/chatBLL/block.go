@@ -0,0 +1,87 @@
+package chatBLL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 屏蔽玩家（被屏蔽玩家的消息不再推送给自己，且其不能再向自己发送私聊消息）
+// clientObj：客户端对象
+// playerObj：玩家对象
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 响应对象
+func AddBlock(clientObj *rpcServer.Client, playerObj *player.Player, blockedId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_AddBlock)
+
+	// 不能屏蔽自己
+	if blockedId == playerObj.Id {
+		return responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
+	}
+
+	// 判断被屏蔽的玩家是否存在
+	if _, exists, err := playerBLL.GetPlayer(blockedId, true); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !exists {
+		return responseObj.SetResultStatus(serverResponseObject.Con_PlayerNotExist)
+	}
+
+	if ok, err := playerBLL.AddBlock(playerObj, blockedId); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !ok {
+		return responseObj.SetResultStatus(model.Con_BlockCountLimit)
+	}
+
+	return sendBlockList(clientObj, playerObj, responseObj)
+}
+
+// 取消屏蔽玩家
+// clientObj：客户端对象
+// playerObj：玩家对象
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 响应对象
+func RemoveBlock(clientObj *rpcServer.Client, playerObj *player.Player, blockedId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_RemoveBlock)
+
+	if err := playerBLL.RemoveBlock(playerObj, blockedId); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	return sendBlockList(clientObj, playerObj, responseObj)
+}
+
+// 获取屏蔽的玩家列表
+// clientObj：客户端对象
+// playerObj：玩家对象
+// 返回值：
+// 响应对象
+func GetBlockList(clientObj *rpcServer.Client, playerObj *player.Player) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_GetBlockList)
+
+	return sendBlockList(clientObj, playerObj, responseObj)
+}
+
+// 向客户端发送最新的屏蔽列表
+// clientObj：客户端对象
+// playerObj：玩家对象
+// responseObj：响应对象
+// 返回值：
+// 响应对象
+func sendBlockList(clientObj *rpcServer.Client, playerObj *player.Player, responseObj *serverResponseObject.ResponseObject) *serverResponseObject.ResponseObject {
+	blockedIdList, err := playerBLL.GetBlockList(playerObj)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 输出结果
+	responseObj.SetData(&blockListResponseData{
+		PlayerIdList: blockedIdList,
+	})
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	return responseObj
+}
@@ -0,0 +1,92 @@
+package chatBLL
+
+import (
+	"net/url"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 检查结构化消息的内容，并只保留该类型有效的字段
+// contentObj：结构化消息的内容（不能为纯文本）
+// 返回值：
+// 只保留有效字段的内容
+// 响应状态（不为Con_Success则表示拒绝发送）
+func checkMessageContent(contentObj *model.MessageContent) (*model.MessageContent, serverResponseObject.ResultStatus) {
+	limitObj, exists := configBLL.GetMessageContentLimit(contentObj.Type)
+	if !exists {
+		return nil, model.Con_MessageContentError
+	}
+
+	// 文本字段不能为空，且不能超过最大长度
+	ifFieldValid := func(fieldList ...string) bool {
+		for _, item := range fieldList {
+			if item == "" || len(item) > limitObj.MaxFieldLength {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// 数值不能小于等于0，且不能超过最大值（最大值<=0表示不限制）
+	ifNumberValid := func(value, maxValue int) bool {
+		return value > 0 && (maxValue <= 0 || value <= maxValue)
+	}
+
+	// 只允许http、https地址，以免客户端加载本地文件等
+	ifUrlValid := func(rawUrl string) bool {
+		if !ifFieldValid(rawUrl) {
+			return false
+		}
+
+		urlObj, err := url.Parse(rawUrl)
+		return err == nil && (urlObj.Scheme == "http" || urlObj.Scheme == "https") && urlObj.Host != ""
+	}
+
+	resultObj := &model.MessageContent{Type: contentObj.Type}
+	switch contentObj.Type {
+	case model.Con_Content_Emoji:
+		if !ifFieldValid(contentObj.EmojiId) {
+			return nil, model.Con_MessageContentError
+		}
+		resultObj.EmojiId = contentObj.EmojiId
+	case model.Con_Content_Link:
+		if !ifFieldValid(contentObj.LinkType, contentObj.LinkId, contentObj.LinkName) {
+			return nil, model.Con_MessageContentError
+		}
+		resultObj.LinkType, resultObj.LinkId, resultObj.LinkName = contentObj.LinkType, contentObj.LinkId, contentObj.LinkName
+	case model.Con_Content_Voice:
+		if !ifUrlValid(contentObj.Url) || !ifNumberValid(contentObj.Duration, limitObj.MaxDuration) || !ifNumberValid(contentObj.Size, limitObj.MaxSize) {
+			return nil, model.Con_MessageContentError
+		}
+		resultObj.Url, resultObj.Duration, resultObj.Size = contentObj.Url, contentObj.Duration, contentObj.Size
+	case model.Con_Content_Image:
+		if !ifUrlValid(contentObj.Url) || !ifNumberValid(contentObj.Size, limitObj.MaxSize) || contentObj.Width < 0 || contentObj.Height < 0 {
+			return nil, model.Con_MessageContentError
+		}
+		resultObj.Url, resultObj.Size, resultObj.Width, resultObj.Height = contentObj.Url, contentObj.Size, contentObj.Width, contentObj.Height
+	default:
+		return nil, model.Con_MessageContentError
+	}
+
+	return resultObj, serverResponseObject.Con_Success
+}
+
+// 按照频道的配置过滤结构化消息中显示给玩家的文本（链接卡片的名称）
+// _channelType：频道类型
+// contentObj：结构化消息的内容（纯文本时为nil）
+// 返回值：
+// 响应状态（不为Con_Success则表示拒绝发送）
+func filterMessageContent(_channelType channelType.ChannelType, contentObj *model.MessageContent) serverResponseObject.ResultStatus {
+	if contentObj == nil || contentObj.Type != model.Con_Content_Link {
+		return serverResponseObject.Con_Success
+	}
+
+	var resultStatus serverResponseObject.ResultStatus
+	contentObj.LinkName, resultStatus = filterMessage(_channelType, contentObj.LinkName)
+
+	return resultStatus
+}
@@ -0,0 +1,13 @@
+package chatBLL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/config"
+)
+
+// 是否经Center转发、解析信封（未启用时只转发消息内容，收到的消息也不解析信封；回执、聊天室变化只在本服务器处理，撤回不可用）
+// 尚未升级的ChatServer会将信封当作消息内容推送给客户端，故须在所有ChatServer升级完成后才能启用
+// 返回值：
+// 是否转发信封
+func ifSendEnvelope() bool {
+	return config.ChatEnvelopeEnabled
+}
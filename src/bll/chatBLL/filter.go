@@ -0,0 +1,33 @@
+package chatBLL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/wordBLL"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 按照频道的配置过滤消息：检查屏蔽词、处理敏感词、处理消息长度
+// _channelType：频道类型
+// message：消息
+// 返回值：
+// 处理后的消息
+// 响应状态（不为Con_Success则表示拒绝发送）
+func filterMessage(_channelType channelType.ChannelType, message string) (string, serverResponseObject.ResultStatus) {
+	channelFilterObj := configBLL.GetChannelFilter(_channelType)
+
+	// 判断屏蔽词
+	if channelFilterObj.IfCheckForbid && wordBLL.IfContainsForbidWords(message) {
+		return message, serverResponseObject.Con_ContainForbiddenWord
+	}
+
+	// 处理敏感词
+	if channelFilterObj.IfMaskSensitive {
+		message = wordBLL.HandleSensitiveWords(message)
+	}
+
+	// 处理消息长度
+	message = configBLL.HandleChannelMessageLength(channelFilterObj, message)
+
+	return message, serverResponseObject.Con_Success
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/Jordanzuo/ChatServer/src/bll/manageCenterBLL"
 	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
 	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
 	"github.com/Jordanzuo/ChatServerModel/src/channelType"
 	"github.com/Jordanzuo/ChatServerModel/src/commandType"
 	"github.com/Jordanzuo/ChatServerModel/src/player"
@@ -37,6 +39,8 @@ func HandleCenterMessage(forwardObj *transferObject.ForwardObject) {
 		handleSilent(forwardObj.ChatMessageObject)
 	case transferObject.Reload:
 		handleReload(forwardObj.ChatMessageObject)
+	case model.Con_MessageType_DeleteMessage:
+		handleDeleteMessage(forwardObj.ChatMessageObject)
 	default:
 		logUtil.Log(fmt.Sprintf("从Center收到了未定义的类型%s", forwardObj.MessageType), logUtil.Error, true)
 	}
@@ -45,59 +49,123 @@ func HandleCenterMessage(forwardObj *transferObject.ForwardObject) {
 func handleChatMessage(chatMessageObj *transferObject.ChatMessageObject) {
 	responseObj := serverResponseObject.NewResponseObject(commandType.SendMessage)
 
-	// 定义变量
-	var finalPlayerList = make([]*player.Player, 0, 1024)
-	var toPlayerObj *player.Player
-	var ifToPlayerExists bool
-	var err error
-
 	// debugUtil.Printf("chatMessageObj.ChannelType:%v, chatMessageObj.Player:%v\n", chatMessageObj.ChannelType, chatMessageObj.Player)
 
-	switch chatMessageObj.ChannelType {
+	// 解析信封：回执、聊天室变化、撤回单独处理；聊天消息则取出其中的消息内容（未启用信封时不解析，整体视为消息内容）
+	envelopeObj := model.NewMessageEnvelope("", chatMessageObj.Message, nil)
+	if ifSendEnvelope() {
+		envelopeObj = model.DecodeChatEnvelope(chatMessageObj.Message)
+	}
+	switch envelopeObj.Type {
+	case model.Con_Envelope_Receipt:
+		handleReceipt(chatMessageObj.ToPlayerId, envelopeObj)
+		return
+	case model.Con_Envelope_RoomChange:
+		handleRoomChange(chatMessageObj.ToPlayerId, envelopeObj.PlayerId)
+		return
+	case model.Con_Envelope_Recall:
+		handleRecall(chatMessageObj, envelopeObj.MessageId)
+		return
+	}
+	chatMessageObj.Message = envelopeObj.Message
+
+	// 添加到聊天记录的缓存中
+	addHistoryToBuffer(chatMessageObj, envelopeObj)
+
+	// 计算接收消息的玩家
+	finalPlayerList, toPlayerObj, resultStatus := getChatAudience(chatMessageObj.ChannelType, chatMessageObj.Player, chatMessageObj.ToPlayerId)
+	if resultStatus != serverResponseObject.Con_Success {
+		// 私聊的目标玩家不在同一区服，向发送者发送失败回执
+		if resultStatus == model.Con_TargetNotInSameServerGroup && envelopeObj.MessageId != "" {
+			sendReceipt(toPlayerObj, chatMessageObj.Player.Id, model.NewReceiptEnvelope(envelopeObj.MessageId, model.Con_Receipt_Failed, model.Con_TargetNotInSameServerGroup))
+		}
+		return
+	}
+
+	// 成功写入目标玩家的客户端连接之后的回调（私聊时用于发送送达回执）
+	var sentCallback func(*rpcServer.Client)
+
+	if chatMessageObj.ChannelType == channelType.Private {
+		// 目标玩家在本服务器在线，删除发送方保存的离线私聊消息
+		deleteOfflineMessage(chatMessageObj.Player, envelopeObj.MessageId)
+
+		// 写入目标玩家的客户端连接之后，向发送者发送送达回执
+		if envelopeObj.MessageId != "" {
+			fromPlayerId := chatMessageObj.Player.Id
+			sentCallback = func(clientObj *rpcServer.Client) {
+				if clientObj.GetPlayerId() == toPlayerObj.Id {
+					sendReceipt(toPlayerObj, fromPlayerId, model.NewReceiptEnvelope(envelopeObj.MessageId, model.Con_Receipt_Delivered, serverResponseObject.Con_Success))
+				}
+			}
+		}
+	}
+
+	debugUtil.Printf("finalPlayerList:%v\n", finalPlayerList)
+
+	// 记录指标
+	sentMessageCounter.Add(channelTypeLabel(chatMessageObj.ChannelType), float64(len(finalPlayerList)))
+
+	// 设置responseObj的Data属性
+	responseObj.SetData(newChatResponseData(chatMessageObj.ChannelType, chatMessageObj.Message, envelopeObj.Content, chatMessageObj.Player, toPlayerObj, envelopeObj.MessageId, chatMessageObj.ToPlayerId))
+
+	// 向玩家发送消息
+	playerBLL.SendToPlayerWithSentCallback(finalPlayerList, responseObj, sentCallback)
+
+	// 向被提及的玩家推送提及通知
+	sendMention(chatMessageObj, envelopeObj, finalPlayerList)
+}
+
+// 计算聊天消息在本服务器的接收者（已移除屏蔽了发送者的玩家）
+// _channelType：频道类型
+// fromPlayerObj：发送者
+// toPlayerId：目标玩家Id（私聊时）；聊天室频道时为聊天室Id
+// 返回值：
+// 接收者列表
+// 目标玩家（私聊时有效）
+// 响应状态（不为Con_Success则表示不需要发送）
+func getChatAudience(_channelType channelType.ChannelType, fromPlayerObj *player.Player, toPlayerId string) (finalPlayerList []*player.Player, toPlayerObj *player.Player, resultStatus serverResponseObject.ResultStatus) {
+	switch _channelType {
 	case channelType.World:
-		finalPlayerList = playerBLL.GetPlayerListInSameServerGroup(chatMessageObj.Player)
+		finalPlayerList = playerBLL.GetPlayerListInSameServerGroup(fromPlayerObj)
 	case channelType.Union:
-		finalPlayerList = playerBLL.GetPlayerListInSameUnion(chatMessageObj.Player)
+		finalPlayerList = playerBLL.GetPlayerListInSameUnion(fromPlayerObj)
 	case channelType.Private:
 		// 获得目标玩家对象
-		toPlayerObj, ifToPlayerExists, err = playerBLL.GetPlayer(chatMessageObj.ToPlayerId, false)
-		if err != nil {
-			return
-		}
-
-		if !ifToPlayerExists {
-			return
+		var exists bool
+		var err error
+		toPlayerObj, exists, err = playerBLL.GetPlayer(toPlayerId, false)
+		if err != nil || !exists {
+			return nil, nil, serverResponseObject.Con_NotFoundTarget
 		}
 
 		// 判断目标玩家是否在同一区服
 		var selfServerGroupObj *serverGroup.ServerGroup
 		var toServerGroupObj *serverGroup.ServerGroup
-		var exists bool
-		if selfServerGroupObj, _, exists = manageCenterBLL.GetServerGroup(chatMessageObj.Player.PartnerId, chatMessageObj.Player.ServerId); !exists {
-			return
+		if selfServerGroupObj, _, exists = manageCenterBLL.GetServerGroup(fromPlayerObj.PartnerId, fromPlayerObj.ServerId); !exists {
+			return nil, toPlayerObj, serverResponseObject.Con_ServerGroupNotExist
 		}
 		if toServerGroupObj, _, exists = manageCenterBLL.GetServerGroup(toPlayerObj.PartnerId, toPlayerObj.ServerId); !exists {
-			return
+			return nil, toPlayerObj, serverResponseObject.Con_ServerGroupNotExist
 		}
 		if selfServerGroupObj != toServerGroupObj {
-			return
+			return nil, toPlayerObj, model.Con_TargetNotInSameServerGroup
 		}
 
 		// 添加到列表中
-		finalPlayerList = append(finalPlayerList, chatMessageObj.Player, toPlayerObj)
+		finalPlayerList = append(finalPlayerList, fromPlayerObj, toPlayerObj)
 	case channelType.CrossServer:
 		finalPlayerList = playerBLL.GetAllPlayerList()
+	case model.Con_Channel_Room:
+		// 聊天室频道的toPlayerId为聊天室Id
+		finalPlayerList = getRoomOnlinePlayerList(toPlayerId)
 	default:
-		return
+		return nil, nil, serverResponseObject.Con_ClientDataError
 	}
 
-	debugUtil.Printf("finalPlayerList:%v\n", finalPlayerList)
+	// 移除屏蔽了发送者的玩家
+	finalPlayerList = playerBLL.FilterBlocked(finalPlayerList, fromPlayerObj.Id)
 
-	// 设置responseObj的Data属性
-	responseObj.SetData(serverResponseData.NewResponseData(chatMessageObj.ChannelType, chatMessageObj.Message, chatMessageObj.Player, toPlayerObj))
-
-	// 向玩家发送消息
-	playerBLL.SendToPlayer(finalPlayerList, responseObj)
+	return finalPlayerList, toPlayerObj, serverResponseObject.Con_Success
 }
 
 func handlePushMessage(chatMessageObj *transferObject.ChatMessageObject) {
@@ -0,0 +1,111 @@
+package chatBLL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+func init() {
+	// 注册客户端命令的处理器
+	rpcServer.RegisterHandler(commandType.Login, false,
+		func() interface{} { return new(loginRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*loginRequest)
+			return Login(clientObj, request.Id, request.Name, request.UnionId, request.ExtraMsg, request.Sign, request.PartnerId, request.ServerId)
+		})
+
+	rpcServer.RegisterHandler(commandType.Logout, true, nil,
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			return Logout(clientObj, playerObj)
+		})
+
+	rpcServer.RegisterHandler(commandType.UpdatePlayerInfo, true,
+		func() interface{} { return new(updatePlayerInfoRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*updatePlayerInfoRequest)
+			return UpdatePlayerInfo(clientObj, playerObj, request.Name, request.UnionId, request.ExtraMsg)
+		})
+
+	rpcServer.RegisterHandler(commandType.SendMessage, true,
+		func() interface{} { return new(sendMessageRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*sendMessageRequest)
+			return SendMessage(clientObj, playerObj, request.ChannelType, request.Message, request.getToId(), request.ClientMessageId, request.Content)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_FetchHistory, true,
+		func() interface{} { return new(fetchHistoryRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*fetchHistoryRequest)
+			return FetchHistory(clientObj, playerObj, request.ChannelType, request.getToId(), request.Count)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_MarkRead, true,
+		func() interface{} { return new(markReadRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*markReadRequest)
+			return MarkRead(clientObj, playerObj, request.PlayerId, request.MessageId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_AddBlock, true,
+		func() interface{} { return new(blockRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*blockRequest)
+			return AddBlock(clientObj, playerObj, request.PlayerId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_RemoveBlock, true,
+		func() interface{} { return new(blockRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*blockRequest)
+			return RemoveBlock(clientObj, playerObj, request.PlayerId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_GetBlockList, true, nil,
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			return GetBlockList(clientObj, playerObj)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_CreateRoom, true,
+		func() interface{} { return new(createRoomRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*createRoomRequest)
+			return CreateRoom(clientObj, playerObj, request.Name, request.IsPublic)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_JoinRoom, true,
+		func() interface{} { return new(roomRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*roomRequest)
+			return JoinRoom(clientObj, playerObj, request.RoomId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_LeaveRoom, true,
+		func() interface{} { return new(roomRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*roomRequest)
+			return LeaveRoom(clientObj, playerObj, request.RoomId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_InviteRoom, true,
+		func() interface{} { return new(inviteRoomRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*inviteRoomRequest)
+			return InviteRoom(clientObj, playerObj, request.RoomId, request.PlayerId)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_GetRoomList, true, nil,
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			return GetRoomList(clientObj, playerObj)
+		})
+
+	rpcServer.RegisterHandler(model.Con_Command_RecallMessage, true,
+		func() interface{} { return new(recallMessageRequest) },
+		func(clientObj *rpcServer.Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject {
+			request := requestObj.(*recallMessageRequest)
+			return RecallMessage(clientObj, playerObj, request.MessageId)
+		})
+}
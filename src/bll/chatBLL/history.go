@@ -0,0 +1,448 @@
+package chatBLL
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/manageCenterBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/roomBLL"
+	"github.com/Jordanzuo/ChatServer/src/config"
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 每个会话缓存的聊天记录数量（也是客户端一次可以获取的最大数量）
+	con_MaxHistoryCount = 50
+
+	// 客户端未指定数量时获取的聊天记录数量
+	con_DefaultHistoryCount = 20
+
+	// 会话缓存的过期时间（超过该时间未被获取的会话缓存会被清理）
+	con_HistoryBufferExpireTime = 30 * time.Minute
+
+	// 没有消息Id的聊天记录（未启用信封时）与数据库中的记录视为同一条消息的最大发送时间差
+	con_HistoryDuplicateDuration = 10 * time.Second
+)
+
+// 会话的聊天记录缓存（只缓存被获取过的会话；首次获取时从数据库加载）
+type historyBuffer struct {
+	// 是否已从数据库加载
+	isLoaded bool
+
+	// 聊天记录列表（按发送时间从早到晚，最多con_MaxHistoryCount条）
+	historyList []*model.ChatHistory
+
+	// 最后一次被获取的时间
+	lastAccessTime time.Time
+}
+
+// 添加聊天记录（超过容量时移除最早的）
+// historyObj：聊天记录
+func (b *historyBuffer) add(historyObj *model.ChatHistory) {
+	// 从数据库加载的记录可能已包含本条消息（发送方在转发到Center之前已保存）
+	if b.contains(historyObj) {
+		return
+	}
+
+	b.historyList = append(b.historyList, historyObj)
+	if len(b.historyList) > con_MaxHistoryCount {
+		b.historyList = b.historyList[len(b.historyList)-con_MaxHistoryCount:]
+	}
+}
+
+// 判断是否已包含从Center收到的聊天记录
+// 有消息Id时按消息Id判断；没有消息Id时（未启用信封），与从数据库加载的记录按发送者、消息内容和发送时间判断
+// historyObj：从Center收到的聊天记录（尚未保存到数据库，故Id为0）
+// 返回值：
+// 是否已包含
+func (b *historyBuffer) contains(historyObj *model.ChatHistory) bool {
+	for _, item := range b.historyList {
+		if historyObj.MessageId != "" {
+			if item.MessageId == historyObj.MessageId {
+				return true
+			}
+			continue
+		}
+
+		if item.Id == 0 || item.PlayerId != historyObj.PlayerId || item.Message != historyObj.Message {
+			continue
+		}
+
+		if duration := historyObj.SendTime.Sub(item.SendTime); duration >= -con_HistoryDuplicateDuration && duration <= con_HistoryDuplicateDuration {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 获取最近的聊天记录
+// count：数量
+// 返回值：
+// 聊天记录列表
+func (b *historyBuffer) getLast(count int) []*model.ChatHistory {
+	if count > len(b.historyList) {
+		count = len(b.historyList)
+	}
+
+	historyList := make([]*model.ChatHistory, count)
+	copy(historyList, b.historyList[len(b.historyList)-count:])
+
+	return historyList
+}
+
+// 聊天记录的响应数据
+type historyResponseData struct {
+	*chatResponseData
+
+	// 发送时间
+	SendTime time.Time
+}
+
+// 获取聊天记录的响应数据
+type historyListResponseData struct {
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 目标玩家Id（私聊时有效）
+	ToPlayerId string
+
+	// 聊天室Id（聊天室频道时有效）
+	RoomId string `json:",omitempty"`
+
+	// 聊天记录列表（按发送时间从早到晚）
+	HistoryList []*historyResponseData
+}
+
+var (
+	// 会话的聊天记录缓存集合，及其锁对象
+	historyBufferMap   = make(map[string]*historyBuffer, 1024)
+	historyBufferMutex sync.Mutex
+)
+
+func init() {
+	go clearExpiredHistoryBuffer()
+}
+
+// 获取会话的键
+// _channelType：频道类型
+// serverGroupId：服务器组Id
+// unionId：公会Id
+// playerId：玩家Id
+// toPlayerId：目标玩家Id
+// 返回值：
+// 会话的键
+func getConversationKey(_channelType channelType.ChannelType, serverGroupId int, unionId, playerId, toPlayerId string) string {
+	switch _channelType {
+	case channelType.World:
+		return fmt.Sprintf("%d_%d", _channelType, serverGroupId)
+	case channelType.Union:
+		return fmt.Sprintf("%d_%d_%s", _channelType, serverGroupId, unionId)
+	case channelType.Private:
+		// 私聊双方使用同一个键
+		if playerId > toPlayerId {
+			playerId, toPlayerId = toPlayerId, playerId
+		}
+		return fmt.Sprintf("%d_%s_%s", _channelType, playerId, toPlayerId)
+	case model.Con_Channel_Room:
+		// 聊天室频道的toPlayerId为聊天室Id
+		return fmt.Sprintf("%d_%s", _channelType, toPlayerId)
+	default:
+		return fmt.Sprintf("%d", _channelType)
+	}
+}
+
+// 保存聊天记录（在发送方所在的服务器保存，以免多个服务器重复保存）
+// messageId：消息Id
+// playerObj：发送者
+// _channelType：频道类型
+// message：消息内容（已过滤）
+// contentObj：结构化消息的内容（纯文本时为nil）
+// toPlayerId：目标玩家Id
+func saveHistory(messageId string, playerObj *player.Player, _channelType channelType.ChannelType, message string, contentObj *model.MessageContent, toPlayerId string) {
+	conversationKey := getConversationKey(_channelType, playerObj.ServerGroupId, playerObj.UnionId, playerObj.Id, toPlayerId)
+	historyObj := model.NewChatHistory(messageId, conversationKey, _channelType, playerObj, toPlayerId, message, time.Now())
+	historyObj.Content = contentObj
+	if err := chatDAL.InsertHistory(historyObj); err != nil {
+		logUtil.Log(fmt.Sprintf("保存聊天记录失败，PlayerId:%s，ChannelType:%d，错误信息为：%s", playerObj.Id, _channelType, err), logUtil.Error, true)
+	}
+}
+
+// 将从Center收到的聊天消息添加到会话缓存中（只添加到已缓存的会话）
+// chatMessageObj：聊天消息对象（Message为信封中的消息内容）
+// envelopeObj：聊天消息的信封
+func addHistoryToBuffer(chatMessageObj *transferObject.ChatMessageObject, envelopeObj *model.ChatEnvelope) {
+	playerObj := chatMessageObj.Player
+	conversationKey := getConversationKey(chatMessageObj.ChannelType, playerObj.ServerGroupId, playerObj.UnionId, playerObj.Id, chatMessageObj.ToPlayerId)
+
+	historyBufferMutex.Lock()
+	defer historyBufferMutex.Unlock()
+
+	if bufferObj, exists := historyBufferMap[conversationKey]; exists {
+		historyObj := model.NewChatHistory(envelopeObj.MessageId, conversationKey, chatMessageObj.ChannelType, playerObj, chatMessageObj.ToPlayerId, chatMessageObj.Message, time.Now())
+		historyObj.Content = envelopeObj.Content
+		bufferObj.add(historyObj)
+	}
+}
+
+// 从会话缓存中移除撤回、删除的消息
+// conversationKey：会话的键
+// messageId：消息Id
+func removeHistoryFromBuffer(conversationKey, messageId string) {
+	historyBufferMutex.Lock()
+	defer historyBufferMutex.Unlock()
+
+	bufferObj, exists := historyBufferMap[conversationKey]
+	if !exists {
+		return
+	}
+
+	for index, item := range bufferObj.historyList {
+		if item.MessageId == messageId {
+			bufferObj.historyList = append(bufferObj.historyList[:index:index], bufferObj.historyList[index+1:]...)
+			return
+		}
+	}
+}
+
+// 获取会话最近的聊天记录（会话未缓存时从数据库加载）
+// conversationKey：会话的键
+// count：数量
+// 返回值：
+// 聊天记录列表
+// 错误对象
+func getHistoryList(conversationKey string, count int) ([]*model.ChatHistory, error) {
+	historyBufferMutex.Lock()
+	bufferObj, exists := historyBufferMap[conversationKey]
+	if exists {
+		if bufferObj.isLoaded {
+			bufferObj.lastAccessTime = time.Now()
+			historyList := bufferObj.getLast(count)
+			historyBufferMutex.Unlock()
+
+			return historyList, nil
+		}
+	} else {
+		// 先创建缓存，以便加载期间从Center收到的消息也被记录下来
+		bufferObj = &historyBuffer{lastAccessTime: time.Now()}
+		historyBufferMap[conversationKey] = bufferObj
+	}
+	historyBufferMutex.Unlock()
+
+	// 正在由其它请求加载，则直接从数据库获取
+	if exists {
+		return chatDAL.GetHistoryList(conversationKey, count)
+	}
+
+	// 从数据库加载
+	loadedHistoryList, err := chatDAL.GetHistoryList(conversationKey, con_MaxHistoryCount)
+
+	historyBufferMutex.Lock()
+	defer historyBufferMutex.Unlock()
+
+	if err != nil {
+		delete(historyBufferMap, conversationKey)
+		return nil, err
+	}
+
+	// 合并加载期间收到的消息
+	receivedHistoryList := bufferObj.historyList
+	bufferObj.historyList = loadedHistoryList
+	for _, item := range receivedHistoryList {
+		bufferObj.add(item)
+	}
+	bufferObj.isLoaded = true
+
+	return bufferObj.getLast(count), nil
+}
+
+// 获取聊天记录
+// clientObj：客户端对象
+// playerObj：玩家对象
+// _channelType：频道类型
+// toPlayerId：目标玩家Id（私聊时有效）；聊天室频道时为聊天室Id
+// count：数量（<=0表示使用默认数量）
+// 返回值：
+// 响应对象
+func FetchHistory(clientObj *rpcServer.Client, playerObj *player.Player, _channelType channelType.ChannelType, toPlayerId string, count int) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_FetchHistory)
+
+	if count <= 0 {
+		count = con_DefaultHistoryCount
+	} else if count > con_MaxHistoryCount {
+		count = con_MaxHistoryCount
+	}
+
+	switch _channelType {
+	case channelType.World, channelType.CrossServer:
+		// 不需要额外的判断
+	case channelType.Union:
+		// 判断公会Id是否为空
+		if playerBLL.IsUnionIdEmpty(playerObj.UnionId) {
+			return responseObj.SetResultStatus(serverResponseObject.Con_NotInUnion)
+		}
+	case channelType.Private:
+		// 目标玩家Id不能为空
+		if toPlayerId == "" {
+			return responseObj.SetResultStatus(serverResponseObject.Con_NotFoundTarget)
+		}
+	case model.Con_Channel_Room:
+		// 只有成员可以获取聊天室的聊天记录
+		if isMember, err := roomBLL.IfMember(toPlayerId, playerObj.Id); err != nil {
+			return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+		} else if !isMember {
+			return responseObj.SetResultStatus(model.Con_NotInRoom)
+		}
+	default:
+		return responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
+	}
+
+	data, err := getHistoryResponseData(playerObj, _channelType, toPlayerId, count)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 输出结果
+	responseObj.SetData(data)
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	return responseObj
+}
+
+// 登陆成功后发送世界频道和公会频道最近的聊天记录
+// clientObj：客户端对象
+// playerObj：玩家对象
+func sendHistoryOnLogin(clientObj *rpcServer.Client, playerObj *player.Player) {
+	if config.LoginHistoryCount <= 0 {
+		return
+	}
+
+	channelTypeList := []channelType.ChannelType{channelType.World}
+	if !playerBLL.IsUnionIdEmpty(playerObj.UnionId) {
+		channelTypeList = append(channelTypeList, channelType.Union)
+	}
+
+	for _, item := range channelTypeList {
+		data, err := getHistoryResponseData(playerObj, item, "", config.LoginHistoryCount)
+		if err != nil {
+			continue
+		}
+
+		responseObj := serverResponseObject.NewResponseObject(model.Con_Command_FetchHistory)
+		responseObj.SetData(data)
+		playerBLL.SendToClient(clientObj, responseObj)
+	}
+}
+
+// 获取聊天记录的响应数据
+// playerObj：玩家对象
+// _channelType：频道类型
+// toPlayerId：目标玩家Id（私聊时有效）；聊天室频道时为聊天室Id
+// count：数量
+// 返回值：
+// 响应数据
+// 错误对象
+func getHistoryResponseData(playerObj *player.Player, _channelType channelType.ChannelType, toPlayerId string, count int) (*historyListResponseData, error) {
+	conversationKey := getConversationKey(_channelType, playerObj.ServerGroupId, playerObj.UnionId, playerObj.Id, toPlayerId)
+	historyList, err := getHistoryList(conversationKey, count)
+	if err != nil {
+		return nil, err
+	}
+
+	// 私聊的目标玩家
+	var toPlayerObj *player.Player
+	if _channelType == channelType.Private {
+		if toPlayerObj, _, err = playerBLL.GetPlayer(toPlayerId, true); err != nil {
+			return nil, err
+		}
+	}
+
+	data := &historyListResponseData{
+		ChannelType: _channelType,
+		HistoryList: make([]*historyResponseData, 0, len(historyList)),
+	}
+	if _channelType == model.Con_Channel_Room {
+		data.RoomId = toPlayerId
+	} else {
+		data.ToPlayerId = toPlayerId
+	}
+
+	for _, item := range historyList {
+		// 不包含已屏蔽的玩家发送的消息
+		if isBlocked, _ := playerBLL.IfBlocked(playerObj.Id, item.PlayerId); isBlocked {
+			continue
+		}
+
+		// 私聊时，发送者是自己则目标是对方，反之亦然
+		var itemToPlayerObj *player.Player
+		if _channelType == channelType.Private {
+			if item.PlayerId == playerObj.Id {
+				itemToPlayerObj = toPlayerObj
+			} else {
+				itemToPlayerObj = playerObj
+			}
+		}
+
+		data.HistoryList = append(data.HistoryList, newHistoryResponseData(item, getHistoryPlayer(item), itemToPlayerObj))
+	}
+
+	return data, nil
+}
+
+// 新建聊天记录的响应数据
+// historyObj：聊天记录
+// fromPlayerObj：发送者
+// toPlayerObj：目标玩家（私聊时有效）
+// 返回值：
+// 聊天记录的响应数据
+func newHistoryResponseData(historyObj *model.ChatHistory, fromPlayerObj, toPlayerObj *player.Player) *historyResponseData {
+	return &historyResponseData{
+		chatResponseData: newChatResponseData(historyObj.ChannelType, historyObj.Message, historyObj.Content, fromPlayerObj, toPlayerObj, historyObj.MessageId, historyObj.ToPlayerId),
+		SendTime:         historyObj.SendTime,
+	}
+}
+
+// 根据聊天记录重建发送者对象（使用发送时的信息）
+// historyObj：聊天记录
+// 返回值：
+// 发送者对象
+func getHistoryPlayer(historyObj *model.ChatHistory) *player.Player {
+	playerObj := player.InitPlayer(historyObj.PlayerId, historyObj.Name, historyObj.PartnerId, historyObj.ServerId, historyObj.UnionId, historyObj.ExtraMsg)
+	if serverGroupObj, serverObj, exists := manageCenterBLL.GetServerGroup(historyObj.PartnerId, historyObj.ServerId); exists {
+		playerObj.SetServerInfo(serverGroupObj.Id, serverObj.Name)
+	}
+
+	return playerObj
+}
+
+// 清理过期的会话缓存
+func clearExpiredHistoryBuffer() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		time.Sleep(5 * time.Minute)
+
+		historyBufferMutex.Lock()
+		now := time.Now()
+		for key, item := range historyBufferMap {
+			if item.isLoaded && now.Sub(item.lastAccessTime) > con_HistoryBufferExpireTime {
+				delete(historyBufferMap, key)
+			}
+		}
+		historyBufferMutex.Unlock()
+	}
+}
@@ -0,0 +1,272 @@
+package chatBLL
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/manageCenterBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 每条消息最多提及的玩家数量（超过的部分忽略）
+	con_MaxMentionCount = 10
+
+	// 登陆时推送的未读提及的最大数量
+	con_MaxUnreadMentionCount = 50
+
+	// 未读提及的保留天数
+	con_UnreadMentionExpireDays = 7
+)
+
+var (
+	// 提及的格式：@{玩家Id}（由客户端在选择玩家后生成，并负责高亮显示）
+	mentionRegexp = regexp.MustCompile(`@\{([^{}\s]+)\}`)
+)
+
+func init() {
+	go clearExpiredUnreadMention()
+}
+
+// 获取未读提及的过期时间点（早于该时间发送的消息视为已过期）
+// 返回值：
+// 过期时间点
+func getUnreadMentionExpireTime() time.Time {
+	return time.Now().AddDate(0, 0, -con_UnreadMentionExpireDays)
+}
+
+// 解析消息中提及的玩家，并只保留与发送者在同一受众中的玩家（世界频道为同一服务器组，公会频道为同一公会）
+// playerObj：发送者
+// _channelType：频道类型（只有世界、公会频道支持提及）
+// message：消息内容（已过滤）
+// 返回值：
+// 有效的被提及的玩家Id列表
+func getMentionIdList(playerObj *player.Player, _channelType channelType.ChannelType, message string) (mentionIdList []string) {
+	for _, id := range parseMentionIdList(playerObj.Id, _channelType, message) {
+		if len(mentionIdList) >= con_MaxMentionCount {
+			break
+		}
+
+		if ifMentionable(playerObj, _channelType, id) {
+			mentionIdList = append(mentionIdList, id)
+		}
+	}
+
+	return
+}
+
+// 解析消息中提及的玩家Id（不验证玩家是否可以被提及）
+// fromPlayerId：发送者的玩家Id
+// _channelType：频道类型（只有世界、公会频道支持提及）
+// message：消息内容（已过滤）
+// 返回值：
+// 被提及的玩家Id列表（已忽略重复的和提及自己的）
+func parseMentionIdList(fromPlayerId string, _channelType channelType.ChannelType, message string) (idList []string) {
+	if _channelType != channelType.World && _channelType != channelType.Union {
+		return
+	}
+
+	matchList := mentionRegexp.FindAllStringSubmatch(message, -1)
+	if len(matchList) == 0 {
+		return
+	}
+
+	checkedMap := make(map[string]bool, len(matchList))
+	for _, item := range matchList {
+		id := item[1]
+		if id == fromPlayerId || checkedMap[id] {
+			continue
+		}
+		checkedMap[id] = true
+
+		idList = append(idList, id)
+	}
+
+	return
+}
+
+// 判断玩家是否可以被提及
+// playerObj：发送者
+// _channelType：频道类型
+// id：被提及的玩家Id
+// 返回值：
+// 是否可以被提及
+func ifMentionable(playerObj *player.Player, _channelType channelType.ChannelType, id string) bool {
+	// 先从本服务器的在线玩家中取，取不到再从数据库中去取
+	targetPlayerObj, exists, _ := playerBLL.GetPlayer(id, false)
+	if !exists {
+		var err error
+		if targetPlayerObj, exists, err = playerBLL.GetPlayer(id, true); err != nil || !exists {
+			return false
+		}
+	}
+
+	// 必须在同一服务器组
+	if serverGroupObj, _, exists := manageCenterBLL.GetServerGroup(targetPlayerObj.PartnerId, targetPlayerObj.ServerId); !exists || serverGroupObj.Id != playerObj.ServerGroupId {
+		return false
+	}
+
+	// 公会频道必须在同一公会
+	if _channelType == channelType.Union && targetPlayerObj.UnionId != playerObj.UnionId {
+		return false
+	}
+
+	// 屏蔽了发送者的玩家不会收到消息，也就不能被提及
+	if isBlocked, err := playerBLL.IfBlocked(id, playerObj.Id); err != nil || isBlocked {
+		return false
+	}
+
+	return true
+}
+
+// 保存未读的提及（在发送方所在的服务器、转发到Center之前保存；被提及的玩家在其它服务器在线时，由该服务器推送后删除）
+// 未启用信封时其它服务器无法得知消息Id，也就无法删除，故不保存（否则被提及的玩家会在下次登陆时重复收到）
+// messageId：消息Id
+// playerObj：发送者
+// mentionIdList：被提及的玩家Id列表
+func saveUnreadMention(messageId string, playerObj *player.Player, mentionIdList []string) {
+	if !ifSendEnvelope() {
+		return
+	}
+
+	// 在本服务器在线的玩家可以直接收到
+	offlineIdList := make([]string, 0, len(mentionIdList))
+	for _, id := range mentionIdList {
+		if _, exists, _ := playerBLL.GetPlayer(id, false); !exists {
+			offlineIdList = append(offlineIdList, id)
+		}
+	}
+
+	if len(offlineIdList) == 0 {
+		return
+	}
+
+	if err := chatDAL.InsertUnreadMention(messageId, offlineIdList, time.Now()); err != nil {
+		logUtil.Log(fmt.Sprintf("保存未读提及失败，PlayerId:%s，MessageId:%s，错误信息为：%s", playerObj.Id, messageId, err), logUtil.Error, true)
+	}
+}
+
+// 向本服务器在线的被提及的玩家推送提及通知（以高优先级单独推送，以便客户端提醒）
+// chatMessageObj：聊天消息对象（Message为信封中的消息内容）
+// envelopeObj：聊天消息的信封
+// finalPlayerList：本服务器中接收该消息的玩家列表
+func sendMention(chatMessageObj *transferObject.ChatMessageObject, envelopeObj *model.ChatEnvelope, finalPlayerList []*player.Player) {
+	// 未启用信封时没有经发送方验证的提及列表，由本服务器解析（只推送给接收了该消息的玩家，已排除不在受众中、屏蔽了发送者的玩家）
+	mentionIdList := envelopeObj.MentionIdList
+	if !ifSendEnvelope() {
+		mentionIdList = parseMentionIdList(chatMessageObj.Player.Id, chatMessageObj.ChannelType, chatMessageObj.Message)
+		if len(mentionIdList) > con_MaxMentionCount {
+			mentionIdList = mentionIdList[:con_MaxMentionCount]
+		}
+	}
+
+	if len(mentionIdList) == 0 {
+		return
+	}
+
+	// 发送者在本服务器在线，则表示是本服务器发送的，此时并未为在本服务器在线的玩家保存未读提及
+	_, isLocalSender, _ := playerBLL.GetPlayer(chatMessageObj.Player.Id, false)
+
+	data := &mentionResponseData{
+		MentionList: []*historyResponseData{
+			{
+				chatResponseData: newChatResponseData(chatMessageObj.ChannelType, chatMessageObj.Message, envelopeObj.Content, chatMessageObj.Player, nil, envelopeObj.MessageId, chatMessageObj.ToPlayerId),
+				SendTime:         time.Now(),
+			},
+		},
+	}
+
+	for _, id := range mentionIdList {
+		// 只推送给接收了该消息的玩家（已排除不在受众中、屏蔽了发送者的玩家）
+		var targetPlayerObj *player.Player
+		for _, item := range finalPlayerList {
+			if item.Id == id {
+				targetPlayerObj = item
+				break
+			}
+		}
+		if targetPlayerObj == nil || targetPlayerObj.ClientId <= 0 {
+			continue
+		}
+
+		clientObj, exists := rpcServer.GetClient(targetPlayerObj.ClientId)
+		if !exists {
+			continue
+		}
+
+		responseObj := serverResponseObject.NewResponseObject(model.Con_Command_Mention)
+		responseObj.SetData(data)
+		rpcServer.ResponseResult(clientObj, responseObj, rpcServer.Con_HighPriority)
+
+		// 删除发送方保存的未读提及
+		if !isLocalSender && envelopeObj.MessageId != "" {
+			if err := chatDAL.DeleteUnreadMention(envelopeObj.MessageId, id); err != nil {
+				logUtil.Log(fmt.Sprintf("删除未读提及失败，PlayerId:%s，MessageId:%s，错误信息为：%s", id, envelopeObj.MessageId, err), logUtil.Error, true)
+			}
+		}
+	}
+}
+
+// 登陆成功后推送离线期间未读的提及（已撤回、删除的消息，以及已屏蔽的发送者的消息不会推送）
+// clientObj：客户端对象
+// playerObj：玩家对象
+func sendMentionOnLogin(clientObj *rpcServer.Client, playerObj *player.Player) {
+	historyList, err := chatDAL.TakeUnreadMentionList(playerObj.Id, getUnreadMentionExpireTime(), con_MaxUnreadMentionCount)
+	if err != nil || len(historyList) == 0 {
+		return
+	}
+
+	data := &mentionResponseData{
+		MentionList: make([]*historyResponseData, 0, len(historyList)),
+	}
+
+	for _, item := range historyList {
+		// 与在线时的规则一致，只能收到同一受众的提及
+		fromPlayerObj := getHistoryPlayer(item)
+		if fromPlayerObj.ServerGroupId != playerObj.ServerGroupId {
+			continue
+		}
+		if item.ChannelType == channelType.Union && item.UnionId != playerObj.UnionId {
+			continue
+		}
+		if isBlocked, _ := playerBLL.IfBlocked(playerObj.Id, item.PlayerId); isBlocked {
+			continue
+		}
+
+		data.MentionList = append(data.MentionList, newHistoryResponseData(item, fromPlayerObj, nil))
+	}
+
+	if len(data.MentionList) == 0 {
+		return
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_Mention)
+	responseObj.SetData(data)
+	rpcServer.ResponseResult(clientObj, responseObj, rpcServer.Con_HighPriority)
+}
+
+// 清理过期的未读提及
+func clearExpiredUnreadMention() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		// 放在此处是因为程序刚启动时日志路径、数据库尚未初始化完成
+		time.Sleep(time.Hour)
+
+		chatDAL.DeleteExpiredUnreadMention(getUnreadMentionExpireTime())
+	}
+}
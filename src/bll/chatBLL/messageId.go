@@ -0,0 +1,34 @@
+package chatBLL
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// 本服务器的消息Id前缀（启动时随机生成，以免与其它ChatServer生成的消息Id重复）
+	messageIdPrefix string
+
+	// 消息Id的序号
+	messageIdSeq int64
+)
+
+func init() {
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		panic(fmt.Errorf("生成消息Id前缀失败，错误信息为：%s", err))
+	}
+
+	messageIdPrefix = hex.EncodeToString(randomBytes)
+}
+
+// 生成新的消息Id（所有ChatServer之间唯一）
+// 返回值：
+// 消息Id
+func newMessageId() string {
+	return fmt.Sprintf("%s%s%s", strconv.FormatInt(time.Now().Unix(), 36), messageIdPrefix, strconv.FormatInt(atomic.AddInt64(&messageIdSeq, 1), 36))
+}
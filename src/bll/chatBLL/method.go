@@ -7,7 +7,8 @@ import (
 	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
 	"github.com/Jordanzuo/ChatServer/src/bll/manageCenterBLL"
 	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
-	"github.com/Jordanzuo/ChatServer/src/bll/wordBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/roomBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/ChatServer/src/rpcClient"
 	"github.com/Jordanzuo/ChatServer/src/rpcServer"
 	"github.com/Jordanzuo/ChatServerModel/src/channelType"
@@ -118,12 +119,34 @@ func Login(clientObj *rpcServer.Client, id, name, unionId, extraMsg, sign string
 	// 设置玩家的服务器信息
 	playerObj.SetServerInfo(serverGroupObj.Id, serverObj.Name)
 
+	// 加载屏蔽列表
+	if err = playerBLL.LoadBlockList(playerObj); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
 	// 将玩家对象添加到玩家列表中
 	playerBLL.RegisterPlayer(playerObj)
 
+	// 私聊会话的未读数量（获取失败时不影响登陆，错误已在数据层记录）
+	if loginData, err := getLoginResponseData(playerObj); err == nil {
+		responseObj.SetData(loginData)
+	}
+
 	// 输出结果
 	playerBLL.SendToClient(clientObj, responseObj)
 
+	// 发送最近的聊天记录
+	sendHistoryOnLogin(clientObj, playerObj)
+
+	// 发送有效期内的系统公告
+	sendAnnouncementOnLogin(clientObj, playerObj)
+
+	// 推送离线私聊消息
+	sendOfflineMessageOnLogin(clientObj, playerObj)
+
+	// 推送离线期间未读的提及
+	sendMentionOnLogin(clientObj, playerObj)
+
 	return responseObj
 }
 
@@ -189,10 +212,18 @@ func UpdatePlayerInfo(clientObj *rpcServer.Client, playerObj *player.Player, nam
 	return responseObj
 }
 
-// 发送消息
-func SendMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channelType channelType.ChannelType, message, toPlayerId string) *serverResponseObject.ResponseObject {
+// 发送消息（成功时先向发送者推送带有消息Id的回执；私聊消息在送达或失败时再推送回执）
+// 聊天室频道的toPlayerId为聊天室Id；contentObj为结构化消息的内容（纯文本时为nil，此时与之前的客户端兼容）
+func SendMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channelType channelType.ChannelType, message, toPlayerId, clientMessageId string, contentObj *model.MessageContent) *serverResponseObject.ResponseObject {
 	responseObj := serverResponseObject.NewResponseObject(commandType.SendMessage)
 
+	// 记录指标
+	defer func() {
+		if responseObj.Code == serverResponseObject.Con_Success {
+			receivedMessageCounter.Inc(channelTypeLabel(_channelType))
+		}
+	}()
+
 	// 判断玩家是否被禁言
 	if isInSilent, _ := playerObj.IsInSilent(); isInSilent {
 		return responseObj.SetResultStatus(serverResponseObject.Con_PlayerIsInSilent)
@@ -200,10 +231,7 @@ func SendMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channel
 
 	switch _channelType {
 	case channelType.World:
-		// 如果是世界频道，则判断禁止词汇
-		if wordBLL.IfContainsForbidWords(message) {
-			return responseObj.SetResultStatus(serverResponseObject.Con_ContainForbiddenWord)
-		}
+		// 世界频道不需要额外的判断
 	case channelType.Union:
 		// 判断公会Id是否为空
 		if playerBLL.IsUnionIdEmpty(playerObj.UnionId) {
@@ -219,12 +247,25 @@ func SendMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channel
 		if toPlayerId == playerObj.Id {
 			return responseObj.SetResultStatus(serverResponseObject.Con_CantSendMessageToSelf)
 		}
-	case channelType.CrossServer:
-		// 如果是世界频道，则判断禁止词汇
-		if wordBLL.IfContainsForbidWords(message) {
-			return responseObj.SetResultStatus(serverResponseObject.Con_ContainForbiddenWord)
+
+		// 不能给屏蔽了自己的玩家发送消息
+		if isBlocked, err := playerBLL.IfBlocked(toPlayerId, playerObj.Id); err != nil {
+			return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+		} else if isBlocked {
+			return responseObj.SetResultStatus(model.Con_BlockedByTarget)
+		}
+	case model.Con_Channel_Room:
+		// 聊天室频道的toPlayerId为聊天室Id，只有成员可以发送消息
+		if toPlayerId == "" {
+			return responseObj.SetResultStatus(model.Con_RoomNotExist)
 		}
 
+		if isMember, err := roomBLL.IfMember(toPlayerId, playerObj.Id); err != nil {
+			return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+		} else if !isMember {
+			return responseObj.SetResultStatus(model.Con_NotInRoom)
+		}
+	case channelType.CrossServer:
 		// 判断是否可以向所有服务器发送信息
 		// 判断服务器组是否存在
 		if serverGroupObj, _, exists := manageCenterBLL.GetServerGroup(playerObj.PartnerId, playerObj.ServerId); !exists {
@@ -243,8 +284,84 @@ func SendMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channel
 		return responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
 	}
 
+	// 判断发送频率（多次超过限制的玩家会被自动禁言）
+	if ok, isSilent := checkRateLimit(playerObj, _channelType); !ok {
+		if isSilent {
+			return responseObj.SetResultStatus(serverResponseObject.Con_PlayerIsInSilent)
+		}
+
+		return responseObj.SetResultStatus(model.Con_SendMessageTooFrequently)
+	}
+
+	// 消息内容不能伪装成信封（否则会被其它ChatServer当作回执、撤回等处理）
+	if model.IfChatEnvelope(message) || (contentObj != nil && model.IfChatEnvelope(contentObj.LinkName)) {
+		return responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
+	}
+
+	// 检查结构化消息，并以其替代文本作为消息内容（以便检查屏蔽词、保存聊天记录，以及不支持的客户端显示）
+	if contentObj.IfText() {
+		contentObj = nil
+	} else if !ifSendEnvelope() {
+		// 结构化消息的内容只能放在信封中转发
+		return responseObj.SetResultStatus(model.Con_FeatureNotEnabled)
+	} else {
+		var contentStatus serverResponseObject.ResultStatus
+		if contentObj, contentStatus = checkMessageContent(contentObj); contentStatus != serverResponseObject.Con_Success {
+			return responseObj.SetResultStatus(contentStatus)
+		}
+		message = contentObj.GetFallbackMessage()
+	}
+
+	// 判断是否为刷屏消息（重复、相似的消息）
+	if isSpam, action := checkSpam(playerObj, _channelType, message); isSpam {
+		if action == model.Con_SpamAction_ShadowDrop {
+			shadowDropMessage(clientObj, playerObj, _channelType, message, contentObj, toPlayerId, clientMessageId)
+			return responseObj
+		}
+
+		return responseObj.SetResultStatus(model.Con_DuplicateMessage)
+	}
+
+	// 按照频道的配置过滤消息（屏蔽词、敏感词、消息长度）
+	var resultStatus serverResponseObject.ResultStatus
+	if message, resultStatus = filterMessage(_channelType, message); resultStatus != serverResponseObject.Con_Success {
+		return responseObj.SetResultStatus(resultStatus)
+	}
+	if resultStatus = filterMessageContent(_channelType, contentObj); resultStatus != serverResponseObject.Con_Success {
+		return responseObj.SetResultStatus(resultStatus)
+	}
+
+	// 解析消息中提及的玩家
+	mentionIdList := getMentionIdList(playerObj, _channelType, message)
+
+	// 分配消息Id
+	messageId := newMessageId()
+
+	// 保存聊天记录
+	saveHistory(messageId, playerObj, _channelType, message, contentObj, toPlayerId)
+
+	// 目标玩家不在本服务器时，保存离线私聊消息
+	isStoredOffline := false
+	if _channelType == channelType.Private {
+		isStoredOffline = saveOfflineMessage(messageId, playerObj, toPlayerId, message, contentObj)
+	}
+
+	// 被提及的玩家不在本服务器时，保存未读提及
+	if len(mentionIdList) > 0 {
+		saveUnreadMention(messageId, playerObj, mentionIdList)
+	}
+
+	// 推送“服务器已接收”的回执（在转发之前，以保证其先于送达回执）
+	sendSentReceipt(clientObj, playerObj, _channelType, messageId, clientMessageId, isStoredOffline)
+
 	// debugUtil.Printf("playerObj:%v, ServerGroupId:%v\n", playerObj, playerObj.ServerGroupId)
 
+	// 消息Id等扩展信息放在信封中经Center转发（未启用信封时只转发消息内容）
+	if ifSendEnvelope() {
+		envelopeObj := model.NewMessageEnvelope(messageId, message, contentObj)
+		envelopeObj.MentionIdList = mentionIdList
+		message = envelopeObj.Encode()
+	}
 	chatMessageObj := transferObject.NewChatMessageObject(_channelType, strconv.Itoa(playerObj.ServerGroupId), message, playerObj)
 	chatMessageObj.SetToPlayerId(toPlayerId)
 	rpcClient.ChatMessageObjectChannel <- chatMessageObj
@@ -0,0 +1,21 @@
+package chatBLL
+
+import (
+	"strconv"
+
+	"github.com/Jordanzuo/ChatServer/src/metric"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+var (
+	// 收到的客户端消息数量（按频道类型）
+	receivedMessageCounter = metric.NewCounter("chat_messages_received_total", "收到的客户端聊天消息数量", "channel_type")
+
+	// 发送给客户端的消息数量（按频道类型，每个接收者计一次）
+	sentMessageCounter = metric.NewCounter("chat_messages_sent_total", "发送给客户端的聊天消息数量", "channel_type")
+)
+
+// 获取频道类型对应的标签值
+func channelTypeLabel(_channelType channelType.ChannelType) string {
+	return strconv.Itoa(int(_channelType))
+}
@@ -0,0 +1,172 @@
+package chatBLL
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/config"
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+func init() {
+	go clearExpiredOfflineMessage()
+}
+
+// 是否保存离线私聊消息
+// 返回值：
+// 是否保存
+func ifSaveOfflineMessage() bool {
+	return config.OfflineMessageMaxCount > 0
+}
+
+// 获取离线私聊消息的过期时间点（早于该时间发送的消息视为已过期）
+// 返回值：
+// 过期时间点
+func getOfflineMessageExpireTime() time.Time {
+	return time.Now().AddDate(0, 0, -config.OfflineMessageExpireDays)
+}
+
+// 保存离线私聊消息（在发送方所在的服务器、转发到Center之前保存；目标玩家在其它服务器在线时，由该服务器收到消息后删除）
+// 未启用信封时其它服务器无法得知消息Id，也就无法删除，故不保存（否则目标玩家会在下次登陆时重复收到）
+// messageId：消息Id
+// playerObj：发送者
+// toPlayerId：目标玩家Id
+// message：消息内容（已过滤）
+// contentObj：结构化消息的内容（纯文本时为nil）
+// 返回值：
+// 是否已保存
+func saveOfflineMessage(messageId string, playerObj *player.Player, toPlayerId, message string, contentObj *model.MessageContent) bool {
+	if !ifSaveOfflineMessage() || !ifSendEnvelope() {
+		return false
+	}
+
+	// 目标玩家在本服务器在线，则可以直接收到
+	if _, exists, _ := playerBLL.GetPlayer(toPlayerId, false); exists {
+		return false
+	}
+
+	messageObj := model.NewChatHistory(messageId, "", channelType.Private, playerObj, toPlayerId, message, time.Now())
+	messageObj.Content = contentObj
+	if err := chatDAL.InsertOfflineMessage(messageObj, config.OfflineMessageMaxCount); err != nil {
+		logUtil.Log(fmt.Sprintf("保存离线私聊消息失败，PlayerId:%s，ToPlayerId:%s，错误信息为：%s", playerObj.Id, toPlayerId, err), logUtil.Error, true)
+		return false
+	}
+
+	return true
+}
+
+// 目标玩家在本服务器收到了其它服务器发送的私聊消息，删除发送方保存的离线私聊消息
+// fromPlayerObj：发送者
+// messageId：消息Id
+func deleteOfflineMessage(fromPlayerObj *player.Player, messageId string) {
+	if !ifSaveOfflineMessage() || messageId == "" {
+		return
+	}
+
+	// 发送者在本服务器在线，则表示是本服务器发送的，此时并未保存
+	if _, exists, _ := playerBLL.GetPlayer(fromPlayerObj.Id, false); exists {
+		return
+	}
+
+	if err := chatDAL.DeleteOfflineMessage(messageId); err != nil {
+		logUtil.Log(fmt.Sprintf("删除离线私聊消息失败，PlayerId:%s，MessageId:%s，错误信息为：%s", fromPlayerObj.Id, messageId, err), logUtil.Error, true)
+	}
+}
+
+// 登陆成功后推送离线私聊消息（按发送顺序，并带有原始的发送时间）
+// 成功写入客户端连接之后，才删除已推送的消息，并向发送者发送送达回执；不在同一服务器组而未推送的消息保留至过期
+// clientObj：客户端对象
+// playerObj：玩家对象
+func sendOfflineMessageOnLogin(clientObj *rpcServer.Client, playerObj *player.Player) {
+	if !ifSaveOfflineMessage() {
+		return
+	}
+
+	messageList, err := chatDAL.GetOfflineMessageList(playerObj.Id, getOfflineMessageExpireTime())
+	if err != nil || len(messageList) == 0 {
+		return
+	}
+
+	data := &historyListResponseData{
+		ChannelType: channelType.Private,
+		HistoryList: make([]*historyResponseData, 0, len(messageList)),
+	}
+
+	deliveredList := make([]*model.ChatHistory, 0, len(messageList))
+	for _, item := range messageList {
+		// 与在线时的规则一致，只能接收同一服务器组的私聊消息
+		fromPlayerObj := getHistoryPlayer(item)
+		if fromPlayerObj.ServerGroupId != playerObj.ServerGroupId {
+			continue
+		}
+
+		data.HistoryList = append(data.HistoryList, newHistoryResponseData(item, fromPlayerObj, playerObj))
+		deliveredList = append(deliveredList, item)
+	}
+
+	if len(data.HistoryList) == 0 {
+		return
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_OfflineMessage)
+	responseObj.SetData(data)
+	playerBLL.SendToClientWithSentCallback(clientObj, responseObj, func(*rpcServer.Client) {
+		// 在客户端的发送Goroutine中回调，故在独立的goroutine中访问数据库
+		go handleOfflineMessageSent(playerObj, deliveredList)
+	})
+}
+
+// 离线私聊消息成功写入客户端连接之后，删除已推送的消息，并向发送者发送送达回执
+// playerObj：目标玩家
+// deliveredList：已推送的离线私聊消息列表
+func handleOfflineMessageSent(playerObj *player.Player, deliveredList []*model.ChatHistory) {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	idList := make([]int64, 0, len(deliveredList))
+	for _, item := range deliveredList {
+		idList = append(idList, item.Id)
+	}
+
+	if err := chatDAL.DeleteOfflineMessageList(playerObj.Id, idList); err != nil {
+		logUtil.Log(fmt.Sprintf("删除离线私聊消息失败，PlayerId:%s，错误信息为：%s", playerObj.Id, err), logUtil.Error, true)
+	}
+
+	for _, item := range deliveredList {
+		if item.MessageId != "" {
+			sendReceipt(playerObj, item.PlayerId, model.NewReceiptEnvelope(item.MessageId, model.Con_Receipt_Delivered, serverResponseObject.Con_Success))
+		}
+	}
+}
+
+// 清理过期的离线私聊消息
+func clearExpiredOfflineMessage() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		// 放在此处是因为程序刚启动时日志路径、数据库尚未初始化完成
+		time.Sleep(time.Hour)
+
+		if !ifSaveOfflineMessage() {
+			continue
+		}
+
+		chatDAL.DeleteExpiredOfflineMessage(getOfflineMessageExpireTime())
+	}
+}
@@ -0,0 +1,155 @@
+package chatBLL
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 统计被限制次数的时间窗口（用于判断是否自动禁言）
+	con_ViolationWindow = time.Minute
+
+	// 令牌桶的过期时间（超过该时间未使用的令牌桶会被清理，再次使用时视为已补满）
+	con_TokenBucketExpireTime = 5 * time.Minute
+)
+
+// 令牌桶的键
+type tokenBucketKey struct {
+	// 玩家Id
+	playerId string
+
+	// 频道类型
+	channelType channelType.ChannelType
+}
+
+// 令牌桶（每个玩家的每个频道一个）
+type tokenBucket struct {
+	// 当前的令牌数量
+	tokens float64
+
+	// 上次补充令牌的时间
+	lastRefillTime time.Time
+
+	// 统计时间窗口内被限制的次数，及时间窗口的开始时间
+	violationCount     int
+	violationStartTime time.Time
+}
+
+var (
+	// 令牌桶集合，及其锁对象
+	tokenBucketMap   = make(map[tokenBucketKey]*tokenBucket, 1024)
+	tokenBucketMutex sync.Mutex
+)
+
+func init() {
+	go clearExpiredTokenBucket()
+}
+
+// 获取发送消息的令牌
+// playerId：玩家Id
+// rateLimitObj：频道的发送频率限制配置
+// 返回值：
+// 是否获取成功（失败表示发送过于频繁）
+// 是否需要自动禁言
+func takeToken(playerId string, rateLimitObj *model.ChannelRateLimit) (ok bool, ifSilent bool) {
+	tokenBucketMutex.Lock()
+	defer tokenBucketMutex.Unlock()
+
+	now := time.Now()
+	capacity := float64(rateLimitObj.MessageCount)
+	key := tokenBucketKey{playerId: playerId, channelType: rateLimitObj.ChannelType}
+
+	bucketObj, exists := tokenBucketMap[key]
+	if !exists {
+		bucketObj = &tokenBucket{
+			tokens:         capacity,
+			lastRefillTime: now,
+		}
+		tokenBucketMap[key] = bucketObj
+	}
+
+	// 按照流逝的时间补充令牌（配置变小时也不超过容量）
+	bucketObj.tokens += now.Sub(bucketObj.lastRefillTime).Seconds() * rateLimitObj.GetRefillRate()
+	if bucketObj.tokens > capacity {
+		bucketObj.tokens = capacity
+	}
+	bucketObj.lastRefillTime = now
+
+	if bucketObj.tokens >= 1 {
+		bucketObj.tokens--
+		return true, false
+	}
+
+	// 记录被限制的次数
+	if now.Sub(bucketObj.violationStartTime) > con_ViolationWindow {
+		bucketObj.violationCount = 0
+		bucketObj.violationStartTime = now
+	}
+	bucketObj.violationCount++
+
+	if rateLimitObj.IfAutoSilent() && bucketObj.violationCount >= rateLimitObj.SilentThreshold {
+		bucketObj.violationCount = 0
+		return false, true
+	}
+
+	return false, false
+}
+
+// 检查玩家在频道中的发送频率
+// playerObj：玩家对象
+// _channelType：频道类型
+// 返回值：
+// 是否允许发送
+// 是否已被自动禁言
+func checkRateLimit(playerObj *player.Player, _channelType channelType.ChannelType) (ok bool, isSilent bool) {
+	rateLimitObj, exists := configBLL.GetChannelRateLimit(_channelType)
+	if !exists {
+		return true, false
+	}
+
+	ok, ifSilent := takeToken(playerObj.Id, rateLimitObj)
+	if ok || !ifSilent {
+		return ok, false
+	}
+
+	// 自动禁言，并保存到数据库
+	playerObj.SilentEndTime = time.Now().Add(rateLimitObj.GetSilentDuration())
+	if err := playerBLL.UpdateSilentEndTime(playerObj); err != nil {
+		logUtil.Log(fmt.Sprintf("保存玩家%s的自动禁言结束时间失败，错误信息为：%s", playerObj.Id, err), logUtil.Error, true)
+	}
+
+	logUtil.Log(fmt.Sprintf("玩家%s在ChannelType:%d中发送消息过于频繁，自动禁言至%s", playerObj.Id, _channelType, playerObj.SilentEndTime.Format("2006-01-02 15:04:05")), logUtil.Warn, true)
+
+	return false, true
+}
+
+// 清理过期的令牌桶
+func clearExpiredTokenBucket() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		time.Sleep(con_TokenBucketExpireTime)
+
+		tokenBucketMutex.Lock()
+		now := time.Now()
+		for key, item := range tokenBucketMap {
+			if now.Sub(item.lastRefillTime) > con_TokenBucketExpireTime {
+				delete(tokenBucketMap, key)
+			}
+		}
+		tokenBucketMutex.Unlock()
+	}
+}
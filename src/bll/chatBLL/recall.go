@@ -0,0 +1,156 @@
+package chatBLL
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/config"
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcClient"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+// 撤回自己发送的消息（只能在发送后的一段时间内撤回；未启用信封时接收者没有消息Id，无法撤回）
+// clientObj：客户端对象
+// playerObj：玩家对象
+// messageId：消息Id
+// 返回值：
+// 响应对象
+func RecallMessage(clientObj *rpcServer.Client, playerObj *player.Player, messageId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_RecallMessage)
+
+	if !ifSendEnvelope() {
+		return responseObj.SetResultStatus(model.Con_FeatureNotEnabled)
+	}
+
+	if config.RecallWindowSeconds <= 0 {
+		return responseObj.SetResultStatus(model.Con_RecallTimeout)
+	}
+
+	// 只能撤回自己发送的、尚未撤回的消息
+	historyObj, exists, err := chatDAL.GetHistoryByMessageId(messageId)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	if !exists || historyObj.IsDeleted || historyObj.PlayerId != playerObj.Id {
+		return responseObj.SetResultStatus(model.Con_MessageNotExist)
+	}
+
+	if time.Since(historyObj.SendTime) > time.Duration(config.RecallWindowSeconds)*time.Second {
+		return responseObj.SetResultStatus(model.Con_RecallTimeout)
+	}
+
+	if err = deleteMessage(historyObj); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 经Center通知所有ChatServer，由其推送给原消息的接收者（使用发送时的玩家信息，以保证接收者、会话与原消息一致）
+	chatMessageObj := transferObject.NewChatMessageObject(historyObj.ChannelType, strconv.Itoa(historyObj.ServerGroupId), model.NewRecallEnvelope(messageId).Encode(), getHistoryPlayer(historyObj))
+	chatMessageObj.SetToPlayerId(historyObj.ToPlayerId)
+	rpcClient.ChatMessageObjectChannel <- chatMessageObj
+
+	// 输出结果
+	responseObj.SetData(newRecalledResponseData(historyObj, false))
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	return responseObj
+}
+
+// 处理发送者撤回消息（来自于Center）
+// chatMessageObj：聊天消息对象（Player、ToPlayerId与原消息一致）
+// messageId：被撤回的消息Id
+func handleRecall(chatMessageObj *transferObject.ChatMessageObject, messageId string) {
+	playerObj := chatMessageObj.Player
+	conversationKey := getConversationKey(chatMessageObj.ChannelType, playerObj.ServerGroupId, playerObj.UnionId, playerObj.Id, chatMessageObj.ToPlayerId)
+	historyObj := model.NewChatHistory(messageId, conversationKey, chatMessageObj.ChannelType, playerObj, chatMessageObj.ToPlayerId, "", time.Now())
+
+	sendRecalled(historyObj, playerObj, false)
+}
+
+// 处理管理员删除消息（来自于Center；每个ChatServer都会收到，故数据库中的删除是幂等的）
+// chatMessageObj：聊天消息对象（Message为被删除的消息Id）
+func handleDeleteMessage(chatMessageObj *transferObject.ChatMessageObject) {
+	messageId := chatMessageObj.Message
+	historyObj, exists, err := chatDAL.GetHistoryByMessageId(messageId)
+	if err != nil {
+		return
+	}
+
+	if !exists {
+		logUtil.Log(fmt.Sprintf("管理员删除的消息不存在，MessageId:%s", messageId), logUtil.Warn, true)
+		return
+	}
+
+	if err = deleteMessage(historyObj); err != nil {
+		return
+	}
+
+	sendRecalled(historyObj, getHistoryPlayer(historyObj), true)
+}
+
+// 从数据库中删除消息（聊天记录、离线私聊消息）
+// historyObj：聊天记录
+// 返回值：
+// 错误对象
+func deleteMessage(historyObj *model.ChatHistory) error {
+	if err := chatDAL.DeleteHistory(historyObj.MessageId); err != nil {
+		return err
+	}
+
+	if historyObj.ChannelType == channelType.Private {
+		if err := chatDAL.DeleteOfflineMessage(historyObj.MessageId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 从缓存中移除消息，并向本服务器的原消息接收者推送消息被撤回、删除的通知
+// historyObj：聊天记录
+// fromPlayerObj：原消息的发送者
+// isDeletedByModerator：是否由管理员删除
+func sendRecalled(historyObj *model.ChatHistory, fromPlayerObj *player.Player, isDeletedByModerator bool) {
+	removeHistoryFromBuffer(historyObj.ConversationKey, historyObj.MessageId)
+
+	finalPlayerList, _, resultStatus := getChatAudience(historyObj.ChannelType, fromPlayerObj, historyObj.ToPlayerId)
+	if resultStatus != serverResponseObject.Con_Success || len(finalPlayerList) == 0 {
+		return
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_MessageRecalled)
+	responseObj.SetData(newRecalledResponseData(historyObj, isDeletedByModerator))
+	playerBLL.SendToPlayer(finalPlayerList, responseObj)
+}
+
+// 新建消息被撤回、删除的响应数据
+// historyObj：聊天记录
+// isDeletedByModerator：是否由管理员删除
+// 返回值：
+// 响应数据
+func newRecalledResponseData(historyObj *model.ChatHistory, isDeletedByModerator bool) *recalledResponseData {
+	data := &recalledResponseData{
+		MessageId:            historyObj.MessageId,
+		ChannelType:          historyObj.ChannelType,
+		PlayerId:             historyObj.PlayerId,
+		IsDeletedByModerator: isDeletedByModerator,
+	}
+
+	switch historyObj.ChannelType {
+	case channelType.Private:
+		data.ToPlayerId = historyObj.ToPlayerId
+	case model.Con_Channel_Room:
+		data.RoomId = historyObj.ToPlayerId
+	}
+
+	return data
+}
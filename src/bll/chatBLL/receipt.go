@@ -0,0 +1,167 @@
+package chatBLL
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcClient"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 等待私聊消息送达回执的超时时间（超时则认为目标玩家不在线）
+	con_ReceiptTimeout = 10 * time.Second
+)
+
+// 等待送达回执的私聊消息（只在发送者所在的ChatServer上记录）
+type pendingReceipt struct {
+	// 发送者的玩家Id
+	playerId string
+
+	// 客户端发送时指定的消息Id
+	clientMessageId string
+
+	// 是否已保存为离线私聊消息（超时时推送已保存的回执，而不是失败的回执）
+	isStoredOffline bool
+
+	// 超时的定时器
+	timer *time.Timer
+}
+
+var (
+	// 等待送达回执的私聊消息集合，及其锁对象
+	pendingReceiptMap   = make(map[string]*pendingReceipt, 1024)
+	pendingReceiptMutex sync.Mutex
+)
+
+// 向发送者推送“服务器已接收”的回执；如果是私聊消息，则开始等待送达回执（未启用信封时接收方无法得知消息Id，故不等待）
+// clientObj：发送者的客户端对象
+// playerObj：发送者
+// _channelType：频道类型
+// messageId：消息Id
+// clientMessageId：客户端发送时指定的消息Id
+// isStoredOffline：私聊消息是否已保存为离线私聊消息
+func sendSentReceipt(clientObj *rpcServer.Client, playerObj *player.Player, _channelType channelType.ChannelType, messageId, clientMessageId string, isStoredOffline bool) {
+	if _channelType == channelType.Private && ifSendEnvelope() {
+		pendingReceiptMutex.Lock()
+		pendingReceiptMap[messageId] = &pendingReceipt{
+			playerId:        playerObj.Id,
+			clientMessageId: clientMessageId,
+			isStoredOffline: isStoredOffline,
+			timer:           time.AfterFunc(con_ReceiptTimeout, func() { handleReceiptTimeout(messageId) }),
+		}
+		pendingReceiptMutex.Unlock()
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_MessageReceipt)
+	responseObj.SetData(&receiptResponseData{
+		MessageId:       messageId,
+		ClientMessageId: clientMessageId,
+		ReceiptStatus:   model.Con_Receipt_Sent,
+		ResultStatus:    serverResponseObject.Con_Success,
+	})
+	playerBLL.SendToClient(clientObj, responseObj)
+}
+
+// 取出等待送达回执的私聊消息
+// messageId：消息Id
+// 返回值：
+// 等待送达回执的私聊消息（不存在则为nil）
+func takePendingReceipt(messageId string) *pendingReceipt {
+	pendingReceiptMutex.Lock()
+	defer pendingReceiptMutex.Unlock()
+
+	pendingObj, exists := pendingReceiptMap[messageId]
+	if !exists {
+		return nil
+	}
+
+	pendingObj.timer.Stop()
+	delete(pendingReceiptMap, messageId)
+
+	return pendingObj
+}
+
+// 等待送达回执超时：所有ChatServer都没有找到在线的目标玩家，或者Center没有转发
+// 已保存为离线私聊消息的，推送已保存的回执（在目标玩家登陆后再推送送达回执），否则推送失败的回执
+// messageId：消息Id
+func handleReceiptTimeout(messageId string) {
+	pendingObj := takePendingReceipt(messageId)
+	if pendingObj == nil {
+		return
+	}
+
+	receiptStatus := model.Con_Receipt_Failed
+	if pendingObj.isStoredOffline {
+		receiptStatus = model.Con_Receipt_Stored
+	}
+
+	deliverReceipt(pendingObj.playerId, pendingObj.clientMessageId, model.NewReceiptEnvelope(messageId, receiptStatus, model.Con_TargetOffline))
+}
+
+// 在目标玩家所在的ChatServer上，向私聊消息的发送者发送回执（发送者在本服务器则直接推送，否则经Center转发）
+// 会在客户端的发送Goroutine中（写入成功之后的回调）调用，故不能阻塞：与Center的通道已满（如正在重连）时丢弃回执
+// toPlayerObj：私聊消息的目标玩家（回执的发送者）
+// fromPlayerId：私聊消息的发送者的玩家Id（回执的接收者）
+// envelopeObj：回执的信封
+func sendReceipt(toPlayerObj *player.Player, fromPlayerId string, envelopeObj *model.ChatEnvelope) {
+	if _, exists, _ := playerBLL.GetPlayer(fromPlayerId, false); exists {
+		handleReceipt(fromPlayerId, envelopeObj)
+		return
+	}
+
+	// 未启用信封时，发送者所在的ChatServer可能尚未升级，无法转发回执
+	if !ifSendEnvelope() {
+		return
+	}
+
+	chatMessageObj := transferObject.NewChatMessageObject(channelType.Private, strconv.Itoa(toPlayerObj.ServerGroupId), envelopeObj.Encode(), toPlayerObj)
+	chatMessageObj.SetToPlayerId(fromPlayerId)
+
+	select {
+	case rpcClient.ChatMessageObjectChannel <- chatMessageObj:
+	default:
+		logUtil.Log(fmt.Sprintf("发送到ChatServerCenter的通道已满，丢弃回执，MessageId:%s，ReceiptStatus:%d，PlayerId:%s", envelopeObj.MessageId, envelopeObj.ReceiptStatus, fromPlayerId), logUtil.Warn, true)
+	}
+}
+
+// 处理回执（本服务器发送的私聊消息则结束等待，并推送给发送者）
+// playerId：私聊消息的发送者的玩家Id
+// envelopeObj：回执的信封
+func handleReceipt(playerId string, envelopeObj *model.ChatEnvelope) {
+	clientMessageId := ""
+	if pendingObj := takePendingReceipt(envelopeObj.MessageId); pendingObj != nil {
+		clientMessageId = pendingObj.clientMessageId
+	}
+
+	deliverReceipt(playerId, clientMessageId, envelopeObj)
+}
+
+// 将回执推送给在本服务器在线的发送者
+// playerId：私聊消息的发送者的玩家Id
+// clientMessageId：客户端发送时指定的消息Id
+// envelopeObj：回执的信封
+func deliverReceipt(playerId, clientMessageId string, envelopeObj *model.ChatEnvelope) {
+	playerObj, exists, _ := playerBLL.GetPlayer(playerId, false)
+	if !exists {
+		return
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_MessageReceipt)
+	responseObj.SetData(&receiptResponseData{
+		MessageId:       envelopeObj.MessageId,
+		ClientMessageId: clientMessageId,
+		ReceiptStatus:   envelopeObj.ReceiptStatus,
+		ResultStatus:    envelopeObj.ResultStatus,
+	})
+	playerBLL.SendToPlayer([]*player.Player{playerObj}, responseObj)
+}
@@ -0,0 +1,229 @@
+package chatBLL
+
+import (
+	"fmt"
+
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 登陆请求参数
+type loginRequest struct {
+	// 玩家Id
+	Id string
+
+	// 玩家名称
+	Name string
+
+	// 公会Id
+	UnionId string
+
+	// 透传信息
+	ExtraMsg string
+
+	// 签名
+	Sign string
+
+	// 合作商Id
+	PartnerId int
+
+	// 服务器Id
+	ServerId int
+}
+
+func (r *loginRequest) Validate() error {
+	if r.Id == "" {
+		return fmt.Errorf("Id不能为空")
+	}
+
+	if r.Sign == "" {
+		return fmt.Errorf("Sign不能为空")
+	}
+
+	return nil
+}
+
+// 更新玩家信息请求参数
+type updatePlayerInfoRequest struct {
+	// 玩家名称
+	Name string
+
+	// 公会Id
+	UnionId string
+
+	// 透传信息
+	ExtraMsg string
+}
+
+// 发送消息请求参数
+type sendMessageRequest struct {
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 消息内容
+	Message string
+
+	// 目标玩家Id（私聊时有效）
+	ToPlayerId string
+
+	// 聊天室Id（聊天室频道时有效）
+	RoomId string
+
+	// 客户端指定的消息Id（可选；在回执中原样返回，用于客户端对应发送的消息）
+	ClientMessageId string
+
+	// 结构化消息的内容（可选；为空表示纯文本消息）
+	Content *model.MessageContent
+}
+
+// 获取目标Id（聊天室频道时为聊天室Id，其它频道为目标玩家Id）
+func (r *sendMessageRequest) getToId() string {
+	if r.ChannelType == model.Con_Channel_Room {
+		return r.RoomId
+	}
+
+	return r.ToPlayerId
+}
+
+func (r *sendMessageRequest) Validate() error {
+	switch r.ChannelType {
+	case channelType.World, channelType.Union, channelType.Private, channelType.CrossServer, model.Con_Channel_Room:
+		return nil
+	default:
+		return fmt.Errorf("ChannelType:%d未定义", r.ChannelType)
+	}
+}
+
+// 获取聊天记录请求参数
+type fetchHistoryRequest struct {
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 目标玩家Id（私聊时有效）
+	ToPlayerId string
+
+	// 聊天室Id（聊天室频道时有效）
+	RoomId string
+
+	// 数量（<=0表示使用默认数量）
+	Count int
+}
+
+// 获取目标Id（聊天室频道时为聊天室Id，其它频道为目标玩家Id）
+func (r *fetchHistoryRequest) getToId() string {
+	if r.ChannelType == model.Con_Channel_Room {
+		return r.RoomId
+	}
+
+	return r.ToPlayerId
+}
+
+func (r *fetchHistoryRequest) Validate() error {
+	switch r.ChannelType {
+	case channelType.World, channelType.Union, channelType.Private, channelType.CrossServer, model.Con_Channel_Room:
+		return nil
+	default:
+		return fmt.Errorf("ChannelType:%d未定义", r.ChannelType)
+	}
+}
+
+// 将私聊会话标记为已读请求参数
+type markReadRequest struct {
+	// 会话对方的玩家Id
+	PlayerId string
+
+	// 已读的最后一条消息的Id
+	MessageId string
+}
+
+func (r *markReadRequest) Validate() error {
+	if r.PlayerId == "" {
+		return fmt.Errorf("PlayerId不能为空")
+	}
+
+	if r.MessageId == "" {
+		return fmt.Errorf("MessageId不能为空")
+	}
+
+	return nil
+}
+
+// 屏蔽、取消屏蔽玩家请求参数
+type blockRequest struct {
+	// 被屏蔽的玩家Id
+	PlayerId string
+}
+
+func (r *blockRequest) Validate() error {
+	if r.PlayerId == "" {
+		return fmt.Errorf("PlayerId不能为空")
+	}
+
+	return nil
+}
+
+// 创建聊天室请求参数
+type createRoomRequest struct {
+	// 名称
+	Name string
+
+	// 是否公开
+	IsPublic bool
+}
+
+func (r *createRoomRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("Name不能为空")
+	}
+
+	return nil
+}
+
+// 加入、离开聊天室请求参数
+type roomRequest struct {
+	// 聊天室Id
+	RoomId string
+}
+
+func (r *roomRequest) Validate() error {
+	if r.RoomId == "" {
+		return fmt.Errorf("RoomId不能为空")
+	}
+
+	return nil
+}
+
+// 邀请玩家加入聊天室请求参数
+type inviteRoomRequest struct {
+	// 聊天室Id
+	RoomId string
+
+	// 被邀请的玩家Id
+	PlayerId string
+}
+
+func (r *inviteRoomRequest) Validate() error {
+	if r.RoomId == "" {
+		return fmt.Errorf("RoomId不能为空")
+	}
+
+	if r.PlayerId == "" {
+		return fmt.Errorf("PlayerId不能为空")
+	}
+
+	return nil
+}
+
+// 撤回消息请求参数
+type recallMessageRequest struct {
+	// 消息Id
+	MessageId string
+}
+
+func (r *recallMessageRequest) Validate() error {
+	if r.MessageId == "" {
+		return fmt.Errorf("MessageId不能为空")
+	}
+
+	return nil
+}
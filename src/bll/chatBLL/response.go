@@ -0,0 +1,138 @@
+package chatBLL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseData"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 聊天消息的响应数据（在ChatServerModel定义的数据之外增加消息Id）
+type chatResponseData struct {
+	*serverResponseData.ResponseData
+
+	// 消息Id（后台推送等没有消息Id的消息为空）
+	MessageId string
+
+	// 聊天室Id（聊天室频道时有效）
+	RoomId string `json:",omitempty"`
+
+	// 结构化消息的内容（纯文本时为空；不支持的客户端只显示Message中的替代文本）
+	Content *model.MessageContent `json:",omitempty"`
+}
+
+// 新建聊天消息的响应数据
+// _channelType：频道类型
+// message：消息内容
+// contentObj：结构化消息的内容（纯文本时为nil）
+// fromPlayerObj：发送者
+// toPlayerObj：目标玩家（私聊时有效）
+// messageId：消息Id
+// toId：目标Id（聊天室频道时为聊天室Id）
+// 返回值：
+// 聊天消息的响应数据
+func newChatResponseData(_channelType channelType.ChannelType, message string, contentObj *model.MessageContent, fromPlayerObj, toPlayerObj *player.Player, messageId, toId string) *chatResponseData {
+	data := &chatResponseData{
+		ResponseData: serverResponseData.NewResponseData(_channelType, message, fromPlayerObj, toPlayerObj),
+		MessageId:    messageId,
+		Content:      contentObj,
+	}
+	if _channelType == model.Con_Channel_Room {
+		data.RoomId = toId
+	}
+
+	return data
+}
+
+// 私聊消息回执的响应数据
+type receiptResponseData struct {
+	// 消息Id
+	MessageId string
+
+	// 客户端发送时指定的消息Id（只有发送者所在的ChatServer知道，其它情况下为空）
+	ClientMessageId string
+
+	// 回执状态
+	ReceiptStatus model.ReceiptStatus
+
+	// 失败的原因（回执状态为失败时有效）
+	ResultStatus serverResponseObject.ResultStatus
+}
+
+// 私聊会话未读数量的响应数据
+type unreadCountResponseData struct {
+	// 会话对方的玩家Id
+	PlayerId string
+
+	// 未读数量
+	UnreadCount int
+}
+
+// 登陆的响应数据
+type loginResponseData struct {
+	// 私聊会话的未读数量列表（只包含有未读消息的会话）
+	UnreadCountList []*unreadCountResponseData
+}
+
+// 屏蔽列表的响应数据
+type blockListResponseData struct {
+	// 被屏蔽的玩家Id列表
+	PlayerIdList []string
+}
+
+// 聊天室的响应数据
+type roomResponseData struct {
+	// 聊天室Id
+	RoomId string
+
+	// 名称
+	Name string
+
+	// 房主的玩家Id
+	OwnerId string
+
+	// 是否公开
+	IsPublic bool
+
+	// 成员的玩家Id列表（聊天室已删除时为空）
+	MemberIdList []string
+}
+
+// 系统公告的响应数据
+type announcementResponseData struct {
+	*serverResponseData.ResponseData
+
+	// 公告Id
+	AnnouncementId int
+
+	// 优先级（越大越优先）
+	Priority int
+}
+
+// 消息被撤回、删除的响应数据
+type recalledResponseData struct {
+	// 消息Id
+	MessageId string
+
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 发送者的玩家Id
+	PlayerId string
+
+	// 目标玩家Id（私聊时有效）
+	ToPlayerId string
+
+	// 聊天室Id（聊天室频道时有效）
+	RoomId string `json:",omitempty"`
+
+	// 是否由管理员删除（否则为发送者撤回）
+	IsDeletedByModerator bool
+}
+
+// 提及（@）自己的消息的响应数据
+type mentionResponseData struct {
+	// 提及自己的消息列表（按发送时间从早到晚；在线时只有一条）
+	MentionList []*historyResponseData
+}
@@ -0,0 +1,361 @@
+package chatBLL
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/manageCenterBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/roomBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcClient"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+)
+
+const (
+	// 聊天室的最大成员数量
+	con_MaxRoomMemberCount = 100
+
+	// 每个玩家最多可以加入的聊天室数量
+	con_MaxRoomCount = 20
+
+	// 聊天室名称的最大长度（字符数）
+	con_MaxRoomNameLength = 32
+)
+
+// 创建聊天室
+// clientObj：客户端对象
+// playerObj：玩家对象
+// name：名称
+// isPublic：是否公开
+// 返回值：
+// 响应对象
+func CreateRoom(clientObj *rpcServer.Client, playerObj *player.Player, name string, isPublic bool) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_CreateRoom)
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > con_MaxRoomNameLength {
+		return responseObj.SetResultStatus(serverResponseObject.Con_NameError)
+	}
+
+	// 判断加入的聊天室数量
+	if status := checkRoomCount(playerObj.Id); status != serverResponseObject.Con_Success {
+		return responseObj.SetResultStatus(status)
+	}
+
+	roomObj := model.NewChatRoom(newMessageId(), name, playerObj.Id, playerObj.ServerGroupId, isPublic, time.Now())
+	if err := roomBLL.CreateRoom(roomObj); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	return sendRoom(clientObj, roomObj.Id, responseObj)
+}
+
+// 加入公开的聊天室
+// clientObj：客户端对象
+// playerObj：玩家对象
+// roomId：聊天室Id
+// 返回值：
+// 响应对象
+func JoinRoom(clientObj *rpcServer.Client, playerObj *player.Player, roomId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_JoinRoom)
+
+	roomObj, memberIdList, exists, err := roomBLL.GetRoom(roomId)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !exists || roomObj.ServerGroupId != playerObj.ServerGroupId {
+		return responseObj.SetResultStatus(model.Con_RoomNotExist)
+	}
+
+	if !roomObj.IsPublic {
+		return responseObj.SetResultStatus(model.Con_RoomIsNotPublic)
+	}
+
+	if status := checkRoomJoinable(playerObj.Id, memberIdList); status != serverResponseObject.Con_Success {
+		return responseObj.SetResultStatus(status)
+	}
+
+	if ok, err := roomBLL.AddMember(roomId, playerObj.Id, con_MaxRoomMemberCount); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !ok {
+		return responseObj.SetResultStatus(model.Con_RoomIsFull)
+	}
+
+	notifyRoomChange(playerObj, roomId, playerObj.Id)
+
+	return sendRoom(clientObj, roomId, responseObj)
+}
+
+// 离开聊天室
+// clientObj：客户端对象
+// playerObj：玩家对象
+// roomId：聊天室Id
+// 返回值：
+// 响应对象
+func LeaveRoom(clientObj *rpcServer.Client, playerObj *player.Player, roomId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_LeaveRoom)
+
+	if isMember, err := roomBLL.IfMember(roomId, playerObj.Id); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !isMember {
+		return responseObj.SetResultStatus(model.Con_NotInRoom)
+	}
+
+	if err := roomBLL.RemoveMember(roomId, playerObj.Id); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	notifyRoomChange(playerObj, roomId, playerObj.Id)
+
+	return sendRoom(clientObj, roomId, responseObj)
+}
+
+// 邀请玩家加入聊天室（成员可以邀请同一服务器组的玩家，被邀请的玩家直接成为成员）
+// clientObj：客户端对象
+// playerObj：玩家对象
+// roomId：聊天室Id
+// toPlayerId：被邀请的玩家Id
+// 返回值：
+// 响应对象
+func InviteRoom(clientObj *rpcServer.Client, playerObj *player.Player, roomId, toPlayerId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_InviteRoom)
+
+	_, memberIdList, exists, err := roomBLL.GetRoom(roomId)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !exists {
+		return responseObj.SetResultStatus(model.Con_RoomNotExist)
+	}
+
+	if !containsPlayerId(memberIdList, playerObj.Id) {
+		return responseObj.SetResultStatus(model.Con_NotInRoom)
+	}
+
+	// 被邀请的玩家必须存在，且在同一服务器组
+	toPlayerObj, exists, err := playerBLL.GetPlayer(toPlayerId, true)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !exists {
+		return responseObj.SetResultStatus(serverResponseObject.Con_PlayerNotExist)
+	}
+
+	if serverGroupObj, _, exists := manageCenterBLL.GetServerGroup(toPlayerObj.PartnerId, toPlayerObj.ServerId); !exists || serverGroupObj.Id != playerObj.ServerGroupId {
+		return responseObj.SetResultStatus(model.Con_TargetNotInSameServerGroup)
+	}
+
+	// 不能邀请屏蔽了自己的玩家
+	if isBlocked, err := playerBLL.IfBlocked(toPlayerId, playerObj.Id); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if isBlocked {
+		return responseObj.SetResultStatus(model.Con_BlockedByTarget)
+	}
+
+	if status := checkRoomJoinable(toPlayerId, memberIdList); status != serverResponseObject.Con_Success {
+		return responseObj.SetResultStatus(status)
+	}
+
+	if ok, err := roomBLL.AddMember(roomId, toPlayerId, con_MaxRoomMemberCount); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	} else if !ok {
+		return responseObj.SetResultStatus(model.Con_RoomIsFull)
+	}
+
+	notifyRoomChange(playerObj, roomId, toPlayerId)
+
+	return sendRoom(clientObj, roomId, responseObj)
+}
+
+// 获取已加入的聊天室列表
+// clientObj：客户端对象
+// playerObj：玩家对象
+// 返回值：
+// 响应对象
+func GetRoomList(clientObj *rpcServer.Client, playerObj *player.Player) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_GetRoomList)
+
+	roomIdList, err := roomBLL.GetPlayerRoomIdList(playerObj.Id)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	roomList := make([]*roomResponseData, 0, len(roomIdList))
+	for _, roomId := range roomIdList {
+		data, err := getRoomResponseData(roomId)
+		if err != nil {
+			return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+		}
+
+		roomList = append(roomList, data)
+	}
+
+	// 输出结果
+	responseObj.SetData(roomList)
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	return responseObj
+}
+
+// 判断玩家加入的聊天室数量是否已达上限
+// playerId：玩家Id
+// 返回值：
+// 响应状态
+func checkRoomCount(playerId string) serverResponseObject.ResultStatus {
+	roomIdList, err := roomBLL.GetPlayerRoomIdList(playerId)
+	if err != nil {
+		return serverResponseObject.Con_DataError
+	}
+
+	if len(roomIdList) >= con_MaxRoomCount {
+		return model.Con_RoomCountLimit
+	}
+
+	return serverResponseObject.Con_Success
+}
+
+// 判断玩家是否可以加入聊天室（已是成员时视为可以加入）
+// playerId：玩家Id
+// memberIdList：聊天室的成员列表
+// 返回值：
+// 响应状态
+func checkRoomJoinable(playerId string, memberIdList []string) serverResponseObject.ResultStatus {
+	if containsPlayerId(memberIdList, playerId) {
+		return serverResponseObject.Con_Success
+	}
+
+	if len(memberIdList) >= con_MaxRoomMemberCount {
+		return model.Con_RoomIsFull
+	}
+
+	return checkRoomCount(playerId)
+}
+
+// 判断玩家Id列表中是否包含指定的玩家
+// playerIdList：玩家Id列表
+// playerId：玩家Id
+// 返回值：
+// 是否包含
+func containsPlayerId(playerIdList []string, playerId string) bool {
+	for _, item := range playerIdList {
+		if item == playerId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 向客户端发送聊天室的最新信息
+// clientObj：客户端对象
+// roomId：聊天室Id
+// responseObj：响应对象
+// 返回值：
+// 响应对象
+func sendRoom(clientObj *rpcServer.Client, roomId string, responseObj *serverResponseObject.ResponseObject) *serverResponseObject.ResponseObject {
+	data, err := getRoomResponseData(roomId)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 输出结果
+	responseObj.SetData(data)
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	return responseObj
+}
+
+// 获取聊天室的响应数据
+// roomId：聊天室Id
+// 返回值：
+// 聊天室的响应数据（聊天室已删除时只有RoomId）
+// 错误对象
+func getRoomResponseData(roomId string) (*roomResponseData, error) {
+	roomObj, memberIdList, exists, err := roomBLL.GetRoom(roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return &roomResponseData{RoomId: roomId, MemberIdList: make([]string, 0)}, nil
+	}
+
+	return &roomResponseData{
+		RoomId:       roomId,
+		Name:         roomObj.Name,
+		OwnerId:      roomObj.OwnerId,
+		IsPublic:     roomObj.IsPublic,
+		MemberIdList: memberIdList,
+	}, nil
+}
+
+// 获取聊天室在本服务器在线的成员列表
+// roomId：聊天室Id
+// 返回值：
+// 在线的成员列表
+func getRoomOnlinePlayerList(roomId string) []*player.Player {
+	_, memberIdList, exists, err := roomBLL.GetRoom(roomId)
+	if err != nil || !exists {
+		return nil
+	}
+
+	finalPlayerList := make([]*player.Player, 0, len(memberIdList))
+	for _, playerId := range memberIdList {
+		if playerObj, exists, _ := playerBLL.GetPlayer(playerId, false); exists {
+			finalPlayerList = append(finalPlayerList, playerObj)
+		}
+	}
+
+	return finalPlayerList
+}
+
+// 经Center通知所有ChatServer聊天室发生了变化（各ChatServer使缓存失效，并推送给在线的成员）
+// 未启用信封时只通知本服务器
+// playerObj：操作的玩家
+// roomId：聊天室Id
+// changedPlayerId：加入或离开的玩家Id
+func notifyRoomChange(playerObj *player.Player, roomId, changedPlayerId string) {
+	if !ifSendEnvelope() {
+		handleRoomChange(roomId, changedPlayerId)
+		return
+	}
+
+	chatMessageObj := transferObject.NewChatMessageObject(model.Con_Channel_Room, strconv.Itoa(playerObj.ServerGroupId), model.NewRoomChangeEnvelope(changedPlayerId).Encode(), playerObj)
+	chatMessageObj.SetToPlayerId(roomId)
+	rpcClient.ChatMessageObjectChannel <- chatMessageObj
+}
+
+// 处理聊天室的变化（来自于Center）
+// roomId：聊天室Id
+// changedPlayerId：加入或离开的玩家Id
+func handleRoomChange(roomId, changedPlayerId string) {
+	roomBLL.Invalidate(roomId)
+
+	data, err := getRoomResponseData(roomId)
+	if err != nil {
+		return
+	}
+
+	// 推送给在线的成员，以及离开的玩家
+	finalPlayerList := getRoomOnlinePlayerList(roomId)
+	if changedPlayerObj, exists, _ := playerBLL.GetPlayer(changedPlayerId, false); exists {
+		isMember := false
+		for _, item := range finalPlayerList {
+			if item == changedPlayerObj {
+				isMember = true
+				break
+			}
+		}
+
+		if !isMember {
+			finalPlayerList = append(finalPlayerList, changedPlayerObj)
+		}
+	}
+
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_RoomChange)
+	responseObj.SetData(data)
+	playerBLL.SendToPlayer(finalPlayerList, responseObj)
+}
@@ -0,0 +1,276 @@
+package chatBLL
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/configBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/bll/wordBLL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 参与检测的消息的最小长度（规范化后的字符数；过短的消息如“好的”、“哈哈”经常重复，故不检测）
+	con_SpamMinMessageLength = 5
+
+	// 每个玩家保留的最近消息数量
+	con_PlayerRecentMessageCount = 16
+
+	// 每个服务器组保留的最近消息数量
+	con_ServerGroupRecentMessageCount = 256
+
+	// 清理过期的最近消息的间隔
+	con_RecentMessageClearInterval = 5 * time.Minute
+)
+
+// 最近发送的消息
+type recentMessage struct {
+	// 发送者的玩家Id
+	playerId string
+
+	// 规范化后的消息
+	text string
+
+	// 规范化后的消息的二元组集合（用于计算相似度）
+	bigramMap map[string]bool
+
+	// 发送时间
+	sendTime time.Time
+}
+
+// 最近发送的消息列表（按时间顺序，超过容量时移除最早的）
+type recentMessageList struct {
+	// 容量
+	capacity int
+
+	// 消息列表
+	messageList []*recentMessage
+}
+
+// 添加消息
+// recentMessageObj：消息对象
+func (l *recentMessageList) add(recentMessageObj *recentMessage) {
+	if len(l.messageList) >= l.capacity {
+		l.messageList = l.messageList[1:]
+	}
+
+	l.messageList = append(l.messageList, recentMessageObj)
+}
+
+// 统计时间窗口内的相似消息数量
+// recentMessageObj：待比较的消息对象
+// spamConfigObj：刷屏检测的配置
+// 返回值：
+// 相似消息数量
+func (l *recentMessageList) countSimilar(recentMessageObj *recentMessage, spamConfigObj *model.SpamConfig) int {
+	count := 0
+	for _, item := range l.messageList {
+		if recentMessageObj.sendTime.Sub(item.sendTime) > spamConfigObj.GetWindow() {
+			continue
+		}
+
+		if getSimilarity(recentMessageObj, item) >= spamConfigObj.Similarity {
+			count++
+		}
+	}
+
+	return count
+}
+
+// 是否已过期（时间窗口内没有消息）
+// now：当前时间
+// window：时间窗口
+// 返回值：
+// 是否已过期
+func (l *recentMessageList) isExpired(now time.Time, window time.Duration) bool {
+	return len(l.messageList) == 0 || now.Sub(l.messageList[len(l.messageList)-1].sendTime) > window
+}
+
+var (
+	// 每个玩家、每个服务器组的最近消息，及其锁对象
+	playerRecentMessageMap      = make(map[string]*recentMessageList, 1024)
+	serverGroupRecentMessageMap = make(map[int]*recentMessageList, 64)
+	recentMessageMutex          sync.Mutex
+)
+
+func init() {
+	go clearExpiredRecentMessage()
+}
+
+// 新建最近发送的消息
+// playerId：发送者的玩家Id
+// text：规范化后的消息
+// 返回值：
+// 消息对象
+func newRecentMessage(playerId, text string) *recentMessage {
+	runeList := []rune(text)
+	bigramMap := make(map[string]bool, len(runeList))
+	for index := 0; index+1 < len(runeList); index++ {
+		bigramMap[string(runeList[index:index+2])] = true
+	}
+
+	return &recentMessage{
+		playerId:  playerId,
+		text:      text,
+		bigramMap: bigramMap,
+		sendTime:  time.Now(),
+	}
+}
+
+// 计算两条消息的相似度（二元组的Dice系数，对插入、替换少量字符不敏感）
+// first：第一条消息
+// second：第二条消息
+// 返回值：
+// 相似度（0~1）
+func getSimilarity(first, second *recentMessage) float64 {
+	if first.text == second.text {
+		return 1
+	}
+
+	if len(first.bigramMap) == 0 || len(second.bigramMap) == 0 {
+		return 0
+	}
+
+	commonCount := 0
+	for bigram := range first.bigramMap {
+		if second.bigramMap[bigram] {
+			commonCount++
+		}
+	}
+
+	return 2 * float64(commonCount) / float64(len(first.bigramMap)+len(second.bigramMap))
+}
+
+// 是否检测频道的刷屏消息（只检测公开的频道；私聊、聊天室中向不同的人发送相同的短语是正常的）
+// _channelType：频道类型
+// 返回值：
+// 是否检测
+func ifCheckSpam(_channelType channelType.ChannelType) bool {
+	switch _channelType {
+	case channelType.World, channelType.Union, channelType.CrossServer:
+		return true
+	default:
+		return false
+	}
+}
+
+// 检测刷屏消息（同一玩家或同一服务器组在时间窗口内的重复、相似消息），并记录本条消息
+// playerObj：玩家对象
+// _channelType：频道类型
+// message：消息
+// 返回值：
+// 是否为刷屏消息
+// 处理方式
+func checkSpam(playerObj *player.Player, _channelType channelType.ChannelType, message string) (isSpam bool, action model.SpamAction) {
+	if !ifCheckSpam(_channelType) {
+		return
+	}
+
+	spamConfigObj := configBLL.GetSpamConfig()
+	if !spamConfigObj.IfEnabled() {
+		return
+	}
+
+	text := wordBLL.NormalizeMessage(message)
+	if len([]rune(text)) < con_SpamMinMessageLength {
+		return
+	}
+
+	recentMessageObj := newRecentMessage(playerObj.Id, text)
+
+	recentMessageMutex.Lock()
+	defer recentMessageMutex.Unlock()
+
+	playerMessageList, exists := playerRecentMessageMap[playerObj.Id]
+	if !exists {
+		playerMessageList = &recentMessageList{capacity: con_PlayerRecentMessageCount}
+		playerRecentMessageMap[playerObj.Id] = playerMessageList
+	}
+
+	serverGroupMessageList, exists := serverGroupRecentMessageMap[playerObj.ServerGroupId]
+	if !exists {
+		serverGroupMessageList = &recentMessageList{capacity: con_ServerGroupRecentMessageCount}
+		serverGroupRecentMessageMap[playerObj.ServerGroupId] = serverGroupMessageList
+	}
+
+	// 无论是否判定为刷屏都记录下来，以便持续刷屏的玩家一直被识别
+	defer func() {
+		playerMessageList.add(recentMessageObj)
+		serverGroupMessageList.add(recentMessageObj)
+	}()
+
+	reason := ""
+	if count := playerMessageList.countSimilar(recentMessageObj, spamConfigObj); spamConfigObj.PlayerRepeatCount > 0 && count >= spamConfigObj.PlayerRepeatCount {
+		reason = fmt.Sprintf("玩家在%d秒内已发送%d条相似消息", spamConfigObj.WindowSeconds, count)
+	} else if count := serverGroupMessageList.countSimilar(recentMessageObj, spamConfigObj); spamConfigObj.ServerGroupRepeatCount > 0 && count >= spamConfigObj.ServerGroupRepeatCount {
+		reason = fmt.Sprintf("服务器组在%d秒内已出现%d条相似消息", spamConfigObj.WindowSeconds, count)
+	} else {
+		return
+	}
+
+	// 记录日志，以便人工审核
+	logUtil.Log(fmt.Sprintf("检测到刷屏消息，PlayerId:%s, ServerGroupId:%d, ChannelType:%d, Action:%d, 原因:%s, Message:%s", playerObj.Id, playerObj.ServerGroupId, _channelType, spamConfigObj.Action, reason, message), logUtil.Warn, true)
+
+	return true, spamConfigObj.Action
+}
+
+// 静默丢弃消息：只回显给发送者本人，使其以为发送成功
+// clientObj：发送者的客户端对象
+// playerObj：发送者
+// _channelType：频道类型
+// message：消息
+// contentObj：结构化消息的内容（纯文本时为nil）
+// toPlayerId：目标玩家Id（私聊时）；聊天室频道时为聊天室Id
+// clientMessageId：客户端发送时指定的消息Id
+func shadowDropMessage(clientObj *rpcServer.Client, playerObj *player.Player, _channelType channelType.ChannelType, message string, contentObj *model.MessageContent, toPlayerId, clientMessageId string) {
+	var toPlayerObj *player.Player
+	if _channelType == channelType.Private {
+		toPlayerObj, _, _ = playerBLL.GetPlayer(toPlayerId, false)
+	}
+
+	// 与正常发送一样推送“服务器已接收”的回执（私聊消息之后会因超时而收到失败回执）
+	messageId := newMessageId()
+	sendSentReceipt(clientObj, playerObj, _channelType, messageId, clientMessageId, false)
+
+	responseObj := serverResponseObject.NewResponseObject(commandType.SendMessage)
+	responseObj.SetData(newChatResponseData(_channelType, message, contentObj, playerObj, toPlayerObj, messageId, toPlayerId))
+	playerBLL.SendToPlayer([]*player.Player{playerObj}, responseObj)
+}
+
+// 清理过期的最近消息
+func clearExpiredRecentMessage() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		time.Sleep(con_RecentMessageClearInterval)
+
+		window := configBLL.GetSpamConfig().GetWindow()
+
+		recentMessageMutex.Lock()
+		now := time.Now()
+		for key, item := range playerRecentMessageMap {
+			if item.isExpired(now, window) {
+				delete(playerRecentMessageMap, key)
+			}
+		}
+		for key, item := range serverGroupRecentMessageMap {
+			if item.isExpired(now, window) {
+				delete(serverGroupRecentMessageMap, key)
+			}
+		}
+		recentMessageMutex.Unlock()
+	}
+}
@@ -0,0 +1,88 @@
+package chatBLL
+
+import (
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/playerBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServer/src/rpcServer"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 将私聊会话标记为已读（直到指定的消息为止），并向对方发送已读回执
+// clientObj：客户端对象
+// playerObj：玩家对象
+// peerId：会话对方的玩家Id
+// messageId：已读的最后一条消息的Id
+// 返回值：
+// 响应对象
+func MarkRead(clientObj *rpcServer.Client, playerObj *player.Player, peerId, messageId string) *serverResponseObject.ResponseObject {
+	responseObj := serverResponseObject.NewResponseObject(model.Con_Command_MarkRead)
+
+	// 消息必须属于该会话
+	conversationKey := getConversationKey(channelType.Private, playerObj.ServerGroupId, playerObj.UnionId, playerObj.Id, peerId)
+	historyId, exists, err := chatDAL.GetHistoryId(conversationKey, messageId)
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	if !exists {
+		return responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
+	}
+
+	if err = chatDAL.UpdateLastReadId(playerObj.Id, peerId, historyId); err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 获取剩余的未读数量
+	unreadCountMap, err := chatDAL.GetUnreadCountMap(playerObj.Id, peerId, getUnreadCountSinceTime())
+	if err != nil {
+		return responseObj.SetResultStatus(serverResponseObject.Con_DataError)
+	}
+
+	// 输出结果
+	responseObj.SetData(&unreadCountResponseData{
+		PlayerId:    peerId,
+		UnreadCount: unreadCountMap[peerId],
+	})
+	playerBLL.SendToClient(clientObj, responseObj)
+
+	// 向对方发送已读回执
+	sendReceipt(playerObj, peerId, model.NewReceiptEnvelope(messageId, model.Con_Receipt_Read, serverResponseObject.Con_Success))
+
+	return responseObj
+}
+
+// 获取统计未读数量的起始时间（与离线私聊消息的保留时间一致，以免登陆时扫描所有的聊天记录）
+// 返回值：
+// 起始时间
+func getUnreadCountSinceTime() time.Time {
+	return getOfflineMessageExpireTime()
+}
+
+// 获取登陆的响应数据
+// playerObj：玩家对象
+// 返回值：
+// 登陆的响应数据
+// 错误对象
+func getLoginResponseData(playerObj *player.Player) (*loginResponseData, error) {
+	unreadCountMap, err := chatDAL.GetUnreadCountMap(playerObj.Id, "", getUnreadCountSinceTime())
+	if err != nil {
+		return nil, err
+	}
+
+	data := &loginResponseData{
+		UnreadCountList: make([]*unreadCountResponseData, 0, len(unreadCountMap)),
+	}
+	for peerId, unreadCount := range unreadCountMap {
+		data.UnreadCountList = append(data.UnreadCountList, &unreadCountResponseData{
+			PlayerId:    peerId,
+			UnreadCount: unreadCount,
+		})
+	}
+
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package configBLL
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/configDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/goutil/debugUtil"
+)
+
+var (
+	// 未结束的系统公告列表（按优先级从高到低），及其锁对象
+	announcementList  = make([]*model.Announcement, 0, 8)
+	announcementMutex sync.RWMutex
+)
+
+func init() {
+	if err := ReloadAnnouncement(); err != nil {
+		panic(fmt.Errorf("初始化系统公告配置失败，错误信息为：%s", err))
+	}
+
+	// 注册重新加载的方法
+	reloadBLL.RegisterReloadFunc("Announcement", ReloadAnnouncement)
+}
+
+// 重新加载系统公告配置
+func ReloadAnnouncement() error {
+	tmpAnnouncementList, err := configDAL.InitAnnouncement(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, item := range tmpAnnouncementList {
+		if item.Message == "" {
+			return fmt.Errorf("Id:%d的系统公告配置不正确，Message不能为空", item.Id)
+		}
+
+		if !item.StartTime.Before(item.EndTime) {
+			return fmt.Errorf("Id:%d的系统公告配置不正确，StartTime必须早于EndTime", item.Id)
+		}
+
+		if _, err = item.GetServerGroupIdList(); err != nil {
+			return fmt.Errorf("Id:%d的系统公告配置不正确，%s", item.Id, err)
+		}
+	}
+
+	sort.SliceStable(tmpAnnouncementList, func(i, j int) bool {
+		return tmpAnnouncementList[i].Priority > tmpAnnouncementList[j].Priority
+	})
+
+	debugUtil.Printf("AnnouncementList:%v\n", tmpAnnouncementList)
+
+	announcementMutex.Lock()
+	defer announcementMutex.Unlock()
+	announcementList = tmpAnnouncementList
+
+	return nil
+}
+
+// 获取未结束的系统公告列表
+// 返回值：
+// 系统公告列表（按优先级从高到低；调用者不能修改）
+func GetAnnouncementList() []*model.Announcement {
+	announcementMutex.RLock()
+	defer announcementMutex.RUnlock()
+
+	return announcementList
+}
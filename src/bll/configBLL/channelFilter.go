@@ -0,0 +1,83 @@
+package configBLL
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/configDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/goutil/debugUtil"
+	"github.com/Jordanzuo/goutil/stringUtil"
+)
+
+var (
+	// 频道的消息过滤配置集合，及其锁对象
+	channelFilterMap   = make(map[channelType.ChannelType]*model.ChannelFilter, 8)
+	channelFilterMutex sync.RWMutex
+)
+
+func init() {
+	if err := ReloadChannelFilter(); err != nil {
+		panic(fmt.Errorf("初始化频道的消息过滤配置失败，错误信息为：%s", err))
+	}
+
+	// 注册重新加载的方法
+	reloadBLL.RegisterReloadFunc("ChannelFilter", ReloadChannelFilter)
+}
+
+// 重新加载频道的消息过滤配置
+func ReloadChannelFilter() error {
+	channelFilterList, err := configDAL.InitChannelFilter()
+	if err != nil {
+		return err
+	}
+
+	tmpChannelFilterMap := make(map[channelType.ChannelType]*model.ChannelFilter, len(channelFilterList))
+	for _, item := range channelFilterList {
+		tmpChannelFilterMap[item.ChannelType] = item
+	}
+
+	debugUtil.Printf("ChannelFilterMap:%v\n", tmpChannelFilterMap)
+
+	channelFilterMutex.Lock()
+	defer channelFilterMutex.Unlock()
+	channelFilterMap = tmpChannelFilterMap
+
+	return nil
+}
+
+// 获取频道的消息过滤配置（没有配置的频道默认检查屏蔽词、处理敏感词，并使用config表中的MaxMessageLength）
+// _channelType：频道类型
+// 返回值：
+// 频道的消息过滤配置
+func GetChannelFilter(_channelType channelType.ChannelType) *model.ChannelFilter {
+	channelFilterMutex.RLock()
+	defer channelFilterMutex.RUnlock()
+
+	if channelFilterObj, exists := channelFilterMap[_channelType]; exists {
+		return channelFilterObj
+	}
+
+	return model.NewChannelFilter(_channelType, true, true, 0)
+}
+
+// 按照频道的配置处理消息长度
+// channelFilterObj：频道的消息过滤配置
+// message：消息
+// 返回值：
+// 处理后的消息
+func HandleChannelMessageLength(channelFilterObj *model.ChannelFilter, message string) string {
+	if channelFilterObj.MaxMessageLength <= 0 {
+		return HandleMessageLength(message)
+	}
+
+	// 按字符数比较，与Substring的截取方式一致（按字节比较会将中文截取为约三分之一的长度）
+	if utf8.RuneCountInString(message) > channelFilterObj.MaxMessageLength {
+		return stringUtil.Substring(message, 0, channelFilterObj.MaxMessageLength)
+	}
+
+	return message
+}
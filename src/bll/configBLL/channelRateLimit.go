@@ -0,0 +1,65 @@
+package configBLL
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/configDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/goutil/debugUtil"
+)
+
+var (
+	// 频道的发送频率限制配置集合，及其锁对象
+	channelRateLimitMap   = make(map[channelType.ChannelType]*model.ChannelRateLimit, 8)
+	channelRateLimitMutex sync.RWMutex
+)
+
+func init() {
+	if err := ReloadChannelRateLimit(); err != nil {
+		panic(fmt.Errorf("初始化频道的发送频率限制配置失败，错误信息为：%s", err))
+	}
+
+	// 注册重新加载的方法
+	reloadBLL.RegisterReloadFunc("ChannelRateLimit", ReloadChannelRateLimit)
+}
+
+// 重新加载频道的发送频率限制配置
+func ReloadChannelRateLimit() error {
+	channelRateLimitList, err := configDAL.InitChannelRateLimit()
+	if err != nil {
+		return err
+	}
+
+	tmpChannelRateLimitMap := make(map[channelType.ChannelType]*model.ChannelRateLimit, len(channelRateLimitList))
+	for _, item := range channelRateLimitList {
+		if item.MessageCount <= 0 || item.IntervalSeconds <= 0 {
+			return fmt.Errorf("ChannelType:%d的发送频率限制配置不正确，MessageCount和IntervalSeconds必须大于0", item.ChannelType)
+		}
+
+		tmpChannelRateLimitMap[item.ChannelType] = item
+	}
+
+	debugUtil.Printf("ChannelRateLimitMap:%v\n", tmpChannelRateLimitMap)
+
+	channelRateLimitMutex.Lock()
+	defer channelRateLimitMutex.Unlock()
+	channelRateLimitMap = tmpChannelRateLimitMap
+
+	return nil
+}
+
+// 获取频道的发送频率限制配置
+// _channelType：频道类型
+// 返回值：
+// 频道的发送频率限制配置
+// 是否存在（不存在则表示不限制）
+func GetChannelRateLimit(_channelType channelType.ChannelType) (*model.ChannelRateLimit, bool) {
+	channelRateLimitMutex.RLock()
+	defer channelRateLimitMutex.RUnlock()
+
+	channelRateLimitObj, exists := channelRateLimitMap[_channelType]
+	return channelRateLimitObj, exists
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
 	"github.com/Jordanzuo/ChatServer/src/dal/configDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/ChatServerModel/src/config"
 	"github.com/Jordanzuo/goutil/debugUtil"
 	"github.com/Jordanzuo/goutil/stringUtil"
@@ -21,6 +22,9 @@ func init() {
 
 var (
 	configObj *config.Config
+
+	// 刷屏检测的配置
+	spamConfigObj *model.SpamConfig
 )
 
 // 获取数据库配置
@@ -28,6 +32,11 @@ func GetConfig() *config.Config {
 	return configObj
 }
 
+// 获取刷屏检测的配置
+func GetSpamConfig() *model.SpamConfig {
+	return spamConfigObj
+}
+
 // 初始化数据库连接
 func Reload() error {
 	var err error
@@ -37,6 +46,24 @@ func Reload() error {
 
 	debugUtil.Printf("Config:%v\n", configObj)
 
+	tmpSpamConfigObj, err := configDAL.InitSpamConfig()
+	if err != nil {
+		return err
+	}
+
+	if tmpSpamConfigObj.IfEnabled() {
+		if tmpSpamConfigObj.Similarity <= 0 || tmpSpamConfigObj.Similarity > 1 {
+			return fmt.Errorf("SpamSimilarity配置不正确，必须在(0, 1]之间，当前的为：%v", tmpSpamConfigObj.Similarity)
+		}
+
+		if tmpSpamConfigObj.Action != model.Con_SpamAction_Reject && tmpSpamConfigObj.Action != model.Con_SpamAction_ShadowDrop {
+			return fmt.Errorf("SpamAction配置不正确，当前的为：%d", tmpSpamConfigObj.Action)
+		}
+	}
+
+	spamConfigObj = tmpSpamConfigObj
+	debugUtil.Printf("SpamConfig:%v\n", spamConfigObj)
+
 	return nil
 }
 
@@ -0,0 +1,64 @@
+package configBLL
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/configDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/goutil/debugUtil"
+)
+
+var (
+	// 结构化消息的限制配置集合，及其锁对象
+	messageContentLimitMap   = make(map[model.MessageContentType]*model.MessageContentLimit, 8)
+	messageContentLimitMutex sync.RWMutex
+)
+
+func init() {
+	if err := ReloadMessageContentLimit(); err != nil {
+		panic(fmt.Errorf("初始化结构化消息的限制配置失败，错误信息为：%s", err))
+	}
+
+	// 注册重新加载的方法
+	reloadBLL.RegisterReloadFunc("MessageContentLimit", ReloadMessageContentLimit)
+}
+
+// 重新加载结构化消息的限制配置
+func ReloadMessageContentLimit() error {
+	messageContentLimitList, err := configDAL.InitMessageContentLimit()
+	if err != nil {
+		return err
+	}
+
+	tmpMessageContentLimitMap := make(map[model.MessageContentType]*model.MessageContentLimit, len(messageContentLimitList))
+	for _, item := range messageContentLimitList {
+		if item.MaxFieldLength <= 0 {
+			return fmt.Errorf("ContentType:%d的结构化消息限制配置不正确，MaxFieldLength必须大于0", item.ContentType)
+		}
+
+		tmpMessageContentLimitMap[item.ContentType] = item
+	}
+
+	debugUtil.Printf("MessageContentLimitMap:%v\n", tmpMessageContentLimitMap)
+
+	messageContentLimitMutex.Lock()
+	defer messageContentLimitMutex.Unlock()
+	messageContentLimitMap = tmpMessageContentLimitMap
+
+	return nil
+}
+
+// 获取结构化消息的限制配置
+// contentType：类型
+// 返回值：
+// 结构化消息的限制配置
+// 是否存在（不存在则表示不允许发送该类型）
+func GetMessageContentLimit(contentType model.MessageContentType) (*model.MessageContentLimit, bool) {
+	messageContentLimitMutex.RLock()
+	defer messageContentLimitMutex.RUnlock()
+
+	messageContentLimitObj, exists := messageContentLimitMap[contentType]
+	return messageContentLimitObj, exists
+}
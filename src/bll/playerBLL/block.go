@@ -0,0 +1,192 @@
+package playerBLL
+
+import (
+	"sync"
+
+	"github.com/Jordanzuo/ChatServer/src/dal/playerDAL"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+)
+
+const (
+	// 每个玩家最多可以屏蔽的玩家数量
+	con_MaxBlockCount = 200
+)
+
+var (
+	// 本服务器在线玩家的屏蔽列表（键为玩家Id，值为被屏蔽的玩家Id集合；首次使用时从数据库加载，玩家下线时移除）
+	blockMap   = make(map[string]map[string]bool, 1024)
+	blockMutex sync.RWMutex
+)
+
+// 获取玩家的屏蔽集合（不存在时从数据库加载）
+// playerId：玩家Id
+// 返回值：
+// 被屏蔽的玩家Id集合
+// 错误对象
+func getBlockSet(playerId string) (map[string]bool, error) {
+	blockMutex.RLock()
+	blockSet, exists := blockMap[playerId]
+	blockMutex.RUnlock()
+	if exists {
+		return blockSet, nil
+	}
+
+	blockedIdList, err := playerDAL.GetBlockList(playerId)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSet = make(map[string]bool, len(blockedIdList))
+	for _, blockedId := range blockedIdList {
+		blockSet[blockedId] = true
+	}
+
+	blockMutex.Lock()
+	defer blockMutex.Unlock()
+
+	// 加载期间可能已被其它goroutine加载，以先加载的为准
+	if existingSet, exists := blockMap[playerId]; exists {
+		return existingSet, nil
+	}
+	blockMap[playerId] = blockSet
+
+	return blockSet, nil
+}
+
+// 加载玩家的屏蔽列表（登陆时调用，以免首次转发消息时再逐个从数据库加载）
+// playerObj：玩家对象
+// 返回值：
+// 错误对象
+func LoadBlockList(playerObj *player.Player) error {
+	_, err := getBlockSet(playerObj.Id)
+	return err
+}
+
+// 移除玩家的屏蔽集合缓存
+// playerId：玩家Id
+func removeBlockSet(playerId string) {
+	blockMutex.Lock()
+	defer blockMutex.Unlock()
+
+	delete(blockMap, playerId)
+}
+
+// 添加屏蔽
+// playerObj：玩家对象
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 是否成功（超过最大数量时为false）
+// 错误对象
+func AddBlock(playerObj *player.Player, blockedId string) (ok bool, err error) {
+	blockSet, err := getBlockSet(playerObj.Id)
+	if err != nil {
+		return
+	}
+
+	blockMutex.RLock()
+	isBlocked, count := blockSet[blockedId], len(blockSet)
+	blockMutex.RUnlock()
+	if isBlocked {
+		return true, nil
+	}
+	if count >= con_MaxBlockCount {
+		return
+	}
+
+	if err = playerDAL.InsertBlock(playerObj.Id, blockedId); err != nil {
+		return
+	}
+
+	blockMutex.Lock()
+	blockSet[blockedId] = true
+	blockMutex.Unlock()
+
+	return true, nil
+}
+
+// 取消屏蔽
+// playerObj：玩家对象
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 错误对象
+func RemoveBlock(playerObj *player.Player, blockedId string) error {
+	blockSet, err := getBlockSet(playerObj.Id)
+	if err != nil {
+		return err
+	}
+
+	if err = playerDAL.DeleteBlock(playerObj.Id, blockedId); err != nil {
+		return err
+	}
+
+	blockMutex.Lock()
+	delete(blockSet, blockedId)
+	blockMutex.Unlock()
+
+	return nil
+}
+
+// 获取玩家屏蔽的玩家Id列表
+// playerObj：玩家对象
+// 返回值：
+// 被屏蔽的玩家Id列表
+// 错误对象
+func GetBlockList(playerObj *player.Player) ([]string, error) {
+	blockSet, err := getBlockSet(playerObj.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	blockMutex.RLock()
+	defer blockMutex.RUnlock()
+
+	blockedIdList := make([]string, 0, len(blockSet))
+	for blockedId := range blockSet {
+		blockedIdList = append(blockedIdList, blockedId)
+	}
+
+	return blockedIdList, nil
+}
+
+// 判断玩家是否屏蔽了另一个玩家（玩家在本服务器在线时使用缓存，否则查询数据库）
+// playerId：玩家Id
+// fromPlayerId：另一个玩家的Id
+// 返回值：
+// 是否屏蔽
+// 错误对象
+func IfBlocked(playerId, fromPlayerId string) (bool, error) {
+	if _, exists, _ := GetPlayer(playerId, false); !exists {
+		return playerDAL.IfBlocked(playerId, fromPlayerId)
+	}
+
+	blockSet, err := getBlockSet(playerId)
+	if err != nil {
+		return false, err
+	}
+
+	blockMutex.RLock()
+	defer blockMutex.RUnlock()
+
+	return blockSet[fromPlayerId], nil
+}
+
+// 从玩家列表中移除屏蔽了发送者的玩家（只使用缓存，不访问数据库：在线玩家在登陆时已加载屏蔽列表，没有缓存的玩家不移除）
+// playerList：玩家列表
+// fromPlayerId：发送者的玩家Id
+// 返回值：
+// 过滤后的玩家列表
+func FilterBlocked(playerList []*player.Player, fromPlayerId string) []*player.Player {
+	blockMutex.RLock()
+	defer blockMutex.RUnlock()
+
+	finalPlayerList := make([]*player.Player, 0, len(playerList))
+	for _, item := range playerList {
+		if item.Id != fromPlayerId && blockMap[item.Id][fromPlayerId] {
+			continue
+		}
+
+		finalPlayerList = append(finalPlayerList, item)
+	}
+
+	return finalPlayerList
+}
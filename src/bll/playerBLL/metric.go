@@ -0,0 +1,23 @@
+package playerBLL
+
+import (
+	"strconv"
+
+	"github.com/Jordanzuo/ChatServer/src/metric"
+)
+
+func init() {
+	// 注册玩家相关的指标
+	metric.RegisterGaugeFunc("chat_logged_in_players", "每个服务器组已登陆的玩家数量", "server_group_id", func() map[string]float64 {
+		valueMap := make(map[string]float64, 64)
+
+		serverGroupPlayerMutex.RLock()
+		defer serverGroupPlayerMutex.RUnlock()
+
+		for serverGroupId, serverGroupPlayerObj := range serverGroupPlayerMap {
+			valueMap[strconv.Itoa(serverGroupId)] = float64(len(serverGroupPlayerObj.GetPlayerList()))
+		}
+
+		return valueMap
+	})
+}
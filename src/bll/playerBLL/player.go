@@ -74,6 +74,9 @@ func UnRegisterPlayer(playerObj *player.Player) {
 	defer playerMutex.Unlock()
 	delete(playerMap, playerObj.Id)
 
+	// 移除屏蔽列表的缓存
+	removeBlockSet(playerObj.Id)
+
 	// 从区服玩家集合中删除
 	serverGroupPlayerMutex.RLock()
 	defer serverGroupPlayerMutex.RUnlock()
@@ -41,6 +41,12 @@ func UpdateInfo(playerObj *player.Player, name, unionId, extraMsg string) error
 	return playerDAL.UpdateInfo(playerObj)
 }
 
+// 更新禁言结束时间
+// playerObj：玩家对象（SilentEndTime已更新）
+func UpdateSilentEndTime(playerObj *player.Player) error {
+	return playerDAL.UpdateSilentEndTime(playerObj)
+}
+
 // 更新登录信息
 // playerObj：玩家对象
 // clientObj：客户端对象
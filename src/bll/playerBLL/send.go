@@ -47,14 +47,34 @@ func SendToClient(clientObj *rpcServer.Client, responseObj *serverResponseObject
 	rpcServer.ResponseResult(clientObj, responseObj, rpcServer.Con_HighPriority)
 }
 
-// 发送数据给玩家
+// 发送数据给客户端，并在成功写入客户端连接之后回调
+// clientObj：客户端对象
+// responseObj：Socket服务器的返回对象
+// callback：回调方法（在客户端的发送Goroutine中执行，故不能阻塞）
+func SendToClientWithSentCallback(clientObj *rpcServer.Client, responseObj *serverResponseObject.ResponseObject, callback func(*rpcServer.Client)) {
+	preparedObj := rpcServer.NewPreparedResponse(responseObj)
+	preparedObj.SetSentCallback(callback)
+	rpcServer.ResponseResultPrepared(clientObj, preparedObj, rpcServer.Con_HighPriority)
+}
+
+// 发送数据给玩家（只序列化一次，所有玩家共享序列化的结果；故调用之后不能再修改responseObj）
 // playerList：玩家列表
 // responseObj：Socket服务器的返回对象
 func SendToPlayer(playerList []*player.Player, responseObj *serverResponseObject.ResponseObject) {
+	SendToPlayerWithSentCallback(playerList, responseObj, nil)
+}
+
+// 发送数据给玩家，并在成功写入每个玩家的客户端连接之后回调
+// playerList：玩家列表
+// responseObj：Socket服务器的返回对象
+// callback：回调方法（在客户端的发送Goroutine中执行，故不能阻塞；为nil表示不需要回调）
+func SendToPlayerWithSentCallback(playerList []*player.Player, responseObj *serverResponseObject.ResponseObject, callback func(*rpcServer.Client)) {
+	preparedObj := rpcServer.NewPreparedResponse(responseObj)
+	preparedObj.SetSentCallback(callback)
 	for _, item := range playerList {
 		if item.ClientId > 0 {
 			if clientObj, ok := rpcServer.GetClient(item.ClientId); ok {
-				rpcServer.ResponseResult(clientObj, responseObj, rpcServer.Con_HighPriority)
+				rpcServer.ResponseResultPrepared(clientObj, preparedObj, rpcServer.Con_HighPriority)
 			}
 		}
 	}
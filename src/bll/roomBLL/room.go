@@ -0,0 +1,327 @@
+package roomBLL
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal/chatDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+const (
+	// 清理过期缓存的间隔
+	con_RoomClearInterval = 10 * time.Minute
+
+	// 缓存的过期时间（超过该时间未被访问则移除）
+	con_RoomExpireDuration = 30 * time.Minute
+
+	// 缓存的重新加载时间（加载超过该时间则从数据库重新加载；其它ChatServer修改成员后未必能通知本服务器，如未启用信封时）
+	con_RoomReloadDuration = time.Minute
+)
+
+// 缓存的聊天室
+type roomCache struct {
+	// 聊天室对象
+	roomObj *model.ChatRoom
+
+	// 成员的玩家Id列表（按加入时间从早到晚），及其集合
+	memberIdList []string
+	memberIdMap  map[string]bool
+
+	// 最近一次访问的时间
+	lastAccessTime time.Time
+
+	// 从数据库加载的时间
+	loadTime time.Time
+}
+
+var (
+	// 聊天室的缓存（首次使用时从数据库加载；其它ChatServer修改成员后经Center通知本服务器使其失效），及其锁对象
+	roomCacheMap   = make(map[string]*roomCache, 256)
+	roomCacheMutex sync.RWMutex
+
+	// 修改聊天室的锁对象（保证数据库与缓存的修改顺序一致）
+	modifyMutex sync.Mutex
+)
+
+func init() {
+	go clearExpiredRoomCache()
+}
+
+// 获取缓存的聊天室（不存在或超过重新加载时间时从数据库加载）
+// roomId：聊天室Id
+// 返回值：
+// 缓存的聊天室
+// 是否存在
+// 错误对象
+func getRoomCache(roomId string) (cacheObj *roomCache, exists bool, err error) {
+	roomCacheMutex.RLock()
+	cacheObj, exists = roomCacheMap[roomId]
+	roomCacheMutex.RUnlock()
+	if exists && time.Since(cacheObj.loadTime) <= con_RoomReloadDuration {
+		return
+	}
+
+	roomObj, exists, err := chatDAL.GetRoom(roomId)
+	if err != nil || !exists {
+		return
+	}
+
+	memberIdList, err := chatDAL.GetRoomMemberList(roomId)
+	if err != nil {
+		exists = false
+		return
+	}
+
+	cacheObj = &roomCache{
+		roomObj:        roomObj,
+		memberIdList:   memberIdList,
+		memberIdMap:    make(map[string]bool, len(memberIdList)),
+		lastAccessTime: time.Now(),
+		loadTime:       time.Now(),
+	}
+	for _, playerId := range memberIdList {
+		cacheObj.memberIdMap[playerId] = true
+	}
+
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	// 加载期间可能已被其它goroutine加载，以先加载的为准（已超过重新加载时间的则替换）
+	if existingObj, ok := roomCacheMap[roomId]; ok && time.Since(existingObj.loadTime) <= con_RoomReloadDuration {
+		cacheObj = existingObj
+	} else {
+		roomCacheMap[roomId] = cacheObj
+	}
+
+	return
+}
+
+// 获取聊天室及其成员列表
+// roomId：聊天室Id
+// 返回值：
+// 聊天室对象
+// 成员的玩家Id列表（副本）
+// 是否存在
+// 错误对象
+func GetRoom(roomId string) (roomObj *model.ChatRoom, memberIdList []string, exists bool, err error) {
+	cacheObj, exists, err := getRoomCache(roomId)
+	if err != nil || !exists {
+		return
+	}
+
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	cacheObj.lastAccessTime = time.Now()
+	roomObj = cacheObj.roomObj
+	memberIdList = make([]string, len(cacheObj.memberIdList))
+	copy(memberIdList, cacheObj.memberIdList)
+
+	return
+}
+
+// 判断玩家是否为聊天室的成员（缓存中不是成员时从数据库重新加载，以免其它ChatServer刚加入的成员被拒绝）
+// roomId：聊天室Id
+// playerId：玩家Id
+// 返回值：
+// 是否为成员（聊天室不存在时为false）
+// 错误对象
+func IfMember(roomId, playerId string) (bool, error) {
+	isMember, err := ifCachedMember(roomId, playerId)
+	if err != nil || isMember {
+		return isMember, err
+	}
+
+	Invalidate(roomId)
+
+	return ifCachedMember(roomId, playerId)
+}
+
+// 根据缓存判断玩家是否为聊天室的成员
+// roomId：聊天室Id
+// playerId：玩家Id
+// 返回值：
+// 是否为成员（聊天室不存在时为false）
+// 错误对象
+func ifCachedMember(roomId, playerId string) (bool, error) {
+	cacheObj, exists, err := getRoomCache(roomId)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	cacheObj.lastAccessTime = time.Now()
+
+	return cacheObj.memberIdMap[playerId], nil
+}
+
+// 获取玩家加入的聊天室Id列表
+// playerId：玩家Id
+// 返回值：
+// 聊天室Id列表
+// 错误对象
+func GetPlayerRoomIdList(playerId string) ([]string, error) {
+	return chatDAL.GetPlayerRoomIdList(playerId)
+}
+
+// 创建聊天室（房主同时成为第一个成员）
+// roomObj：聊天室对象
+// 返回值：
+// 错误对象
+func CreateRoom(roomObj *model.ChatRoom) error {
+	modifyMutex.Lock()
+	defer modifyMutex.Unlock()
+
+	if err := chatDAL.InsertRoom(roomObj); err != nil {
+		return err
+	}
+
+	if err := chatDAL.InsertRoomMember(roomObj.Id, roomObj.OwnerId); err != nil {
+		return err
+	}
+
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	roomCacheMap[roomObj.Id] = &roomCache{
+		roomObj:        roomObj,
+		memberIdList:   []string{roomObj.OwnerId},
+		memberIdMap:    map[string]bool{roomObj.OwnerId: true},
+		lastAccessTime: time.Now(),
+		loadTime:       time.Now(),
+	}
+
+	return nil
+}
+
+// 添加聊天室的成员（在修改的锁内检查成员数量，以免并发加入时超过上限）
+// roomId：聊天室Id
+// playerId：玩家Id
+// maxMemberCount：最大成员数量
+// 返回值：
+// 是否成功（聊天室不存在或成员数量已达上限时为false；已是成员时为true）
+// 错误对象
+func AddMember(roomId, playerId string, maxMemberCount int) (ok bool, err error) {
+	modifyMutex.Lock()
+	defer modifyMutex.Unlock()
+
+	cacheObj, exists, err := getRoomCache(roomId)
+	if err != nil || !exists {
+		return
+	}
+
+	roomCacheMutex.RLock()
+	isMember, count := cacheObj.memberIdMap[playerId], len(cacheObj.memberIdList)
+	roomCacheMutex.RUnlock()
+	if isMember {
+		return true, nil
+	}
+	if count >= maxMemberCount {
+		return
+	}
+
+	if err = chatDAL.InsertRoomMember(roomId, playerId); err != nil {
+		return
+	}
+
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	if cacheObj, exists := roomCacheMap[roomId]; exists && !cacheObj.memberIdMap[playerId] {
+		cacheObj.memberIdList = append(cacheObj.memberIdList, playerId)
+		cacheObj.memberIdMap[playerId] = true
+	}
+
+	return true, nil
+}
+
+// 移除聊天室的成员（最后一个成员离开时删除聊天室；房主离开时由最早加入的成员接替）
+// roomId：聊天室Id
+// playerId：玩家Id
+// 返回值：
+// 错误对象
+func RemoveMember(roomId, playerId string) error {
+	modifyMutex.Lock()
+	defer modifyMutex.Unlock()
+
+	cacheObj, exists, err := getRoomCache(roomId)
+	if err != nil || !exists {
+		return err
+	}
+
+	if err = chatDAL.DeleteRoomMember(roomId, playerId); err != nil {
+		return err
+	}
+
+	roomCacheMutex.Lock()
+	memberIdList := make([]string, 0, len(cacheObj.memberIdList))
+	for _, item := range cacheObj.memberIdList {
+		if item != playerId {
+			memberIdList = append(memberIdList, item)
+		}
+	}
+	cacheObj.memberIdList = memberIdList
+	delete(cacheObj.memberIdMap, playerId)
+
+	// 复制一份房主信息，以免修改时与读取者冲突
+	roomObj := *cacheObj.roomObj
+	roomCacheMutex.Unlock()
+
+	if len(memberIdList) == 0 {
+		if err = chatDAL.DeleteRoom(roomId); err != nil {
+			return err
+		}
+
+		Invalidate(roomId)
+		return nil
+	}
+
+	if roomObj.OwnerId == playerId {
+		roomObj.OwnerId = memberIdList[0]
+		if err = chatDAL.UpdateRoomOwner(&roomObj); err != nil {
+			return err
+		}
+
+		roomCacheMutex.Lock()
+		cacheObj.roomObj = &roomObj
+		roomCacheMutex.Unlock()
+	}
+
+	return nil
+}
+
+// 使聊天室的缓存失效（其它ChatServer修改了聊天室时调用）
+// roomId：聊天室Id
+func Invalidate(roomId string) {
+	roomCacheMutex.Lock()
+	defer roomCacheMutex.Unlock()
+
+	delete(roomCacheMap, roomId)
+}
+
+// 清理过期的聊天室缓存
+func clearExpiredRoomCache() {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	for {
+		time.Sleep(con_RoomClearInterval)
+
+		roomCacheMutex.Lock()
+		now := time.Now()
+		for roomId, item := range roomCacheMap {
+			if now.Sub(item.lastAccessTime) > con_RoomExpireDuration {
+				delete(roomCacheMap, roomId)
+			}
+		}
+		roomCacheMutex.Unlock()
+	}
+}
@@ -5,13 +5,12 @@ import (
 
 	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
 	"github.com/Jordanzuo/ChatServer/src/dal/wordDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/goutil/debugUtil"
-	"github.com/Jordanzuo/goutil/dfaUtil"
 )
 
 var (
-	forbidWordList []string = make([]string, 0, 1024)
-	forbidDFAObj   *dfaUtil.DFAUtil
+	forbidRuleSetObj *wordRuleSet
 )
 
 func init() {
@@ -23,26 +22,34 @@ func init() {
 	reloadBLL.RegisterReloadFunc("Forbid", ReloadForbid)
 }
 
-// 重新加载屏蔽词列表
+// 重新加载屏蔽词列表（加载失败时继续使用原有的规则）
 func ReloadForbid() error {
-	var err error
-	if forbidWordList, err = wordDAL.InitForbid(); err != nil {
+	forbidRuleList, err := wordDAL.InitForbid()
+	if err != nil {
 		return err
 	}
 
-	debugUtil.Printf("ForbidWordList:%v\n", forbidWordList)
+	debugUtil.Printf("ForbidRuleList:%v\n", forbidRuleList)
 
-	// 构造DFAUtil对象
-	forbidDFAObj = dfaUtil.NewDFAUtil(forbidWordList)
+	return buildForbidRuleSet(forbidRuleList)
+}
+
+// 构造屏蔽词规则集合
+// ruleList：规则列表
+// 返回值：
+// 错误对象
+func buildForbidRuleSet(ruleList []*model.WordRule) error {
+	ruleSetObj, err := newWordRuleSet(ruleList)
+	if err != nil {
+		return err
+	}
+
+	forbidRuleSetObj = ruleSetObj
 
 	return nil
 }
 
-// 是否包含屏蔽词
+// 是否包含屏蔽词（先规范化再匹配；白名单中的词汇不视为包含）
 func IfContainsForbidWords(input string) bool {
-	if len(forbidWordList) == 0 {
-		return false
-	}
-
-	return forbidDFAObj.IsMatch(input)
+	return forbidRuleSetObj.isMatch(input)
 }
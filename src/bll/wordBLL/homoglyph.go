@@ -0,0 +1,76 @@
+package wordBLL
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
+	"github.com/Jordanzuo/ChatServer/src/dal/wordDAL"
+	"github.com/Jordanzuo/goutil/debugUtil"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+var (
+	// 形近字（包括繁简体）映射
+	homoglyphMap = make(map[rune]rune, 1024)
+)
+
+func init() {
+	if err := ReloadHomoglyph(); err != nil {
+		panic(fmt.Errorf("初始化形近字列表失败，错误信息为：%s", err))
+	}
+
+	// 注册重新加载的方法
+	reloadBLL.RegisterReloadFunc("Homoglyph", ReloadHomoglyph)
+}
+
+// 重新加载形近字列表（映射变化后需要重新构造屏蔽词、敏感词的规则集合）
+func ReloadHomoglyph() error {
+	tmpHomoglyphMap, err := wordDAL.InitHomoglyph()
+	if err != nil {
+		return err
+	}
+
+	newHomoglyphMap := make(map[rune]rune, len(tmpHomoglyphMap))
+	for source, target := range tmpHomoglyphMap {
+		if utf8.RuneCountInString(source) != 1 || utf8.RuneCountInString(target) != 1 {
+			logUtil.Log(fmt.Sprintf("形近字配置不正确，Source:%s，Target:%s，必须均为单个字符", source, target), logUtil.Warn, true)
+			continue
+		}
+
+		sourceRune, _ := utf8.DecodeRuneInString(source)
+		targetRune, _ := utf8.DecodeRuneInString(target)
+		newHomoglyphMap[sourceRune] = targetRune
+	}
+
+	debugUtil.Printf("HomoglyphMap:%v\n", newHomoglyphMap)
+
+	homoglyphMap = newHomoglyphMap
+
+	// 重新构造规则集合（在init中先于敏感词加载时，敏感词规则集合尚不存在）
+	if forbidRuleSetObj != nil {
+		if err = buildForbidRuleSet(forbidRuleSetObj.ruleList); err != nil {
+			return err
+		}
+	}
+
+	if sensitiveRuleSetObj != nil {
+		if err = buildSensitiveRuleSet(sensitiveRuleSetObj.ruleList); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 获取形近字映射后的字符
+// r：字符
+// 返回值：
+// 映射后的字符（没有映射则原样返回）
+func getHomoglyph(r rune) rune {
+	if target, exists := homoglyphMap[r]; exists {
+		return target
+	}
+
+	return r
+}
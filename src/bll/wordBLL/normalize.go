@@ -0,0 +1,118 @@
+package wordBLL
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// 规范化后的字符对应的原始字符的范围（按rune计算，包含首尾）
+type originalRange struct {
+	start int
+	end   int
+}
+
+// 规范化字符串，以避免玩家通过全角字符、插入空格或标点、大小写混用、繁简体或形近字等方式绕过屏蔽
+// 处理步骤：NFKC规范化、全半角折叠、大小写折叠、形近字映射、去除填充字符（空白、标点、符号、格式控制字符等）
+// input：输入字符串
+// 返回值：
+// 规范化后的字符列表
+// 规范化后的每个字符对应的原始字符的范围
+func normalize(input string) (normalizedRuneList []rune, originalRangeList []originalRange) {
+	runeCount := utf8.RuneCountInString(input)
+	normalizedRuneList = make([]rune, 0, runeCount)
+	originalRangeList = make([]originalRange, 0, runeCount)
+
+	// 按NFKC的分段逐段处理（组合字符与其基字符在同一段中，如e+\u0301组合为é），以便记录与原始字符的对应关系；
+	// 一个原始字符分解为多个字符时（如ﬁ），Iter会分多次返回，直到位置前进后才是完整的一段
+	var iter norm.Iter
+	iter.InitString(norm.NFKC, input)
+
+	start, startRune := 0, 0
+	segment := make([]byte, 0, 16)
+	for !iter.Done() {
+		segment = append(segment, iter.Next()...)
+		end := iter.Pos()
+		if end == start {
+			continue
+		}
+
+		endRune := startRune + utf8.RuneCountInString(input[start:end])
+		for _, item := range width.Fold.String(string(segment)) {
+			item = unicode.ToLower(item)
+			item = getHomoglyph(item)
+
+			if isFiller(item) {
+				continue
+			}
+
+			normalizedRuneList = append(normalizedRuneList, item)
+			originalRangeList = append(originalRangeList, originalRange{start: startRune, end: endRune - 1})
+		}
+
+		segment = segment[:0]
+		start, startRune = end, endRune
+	}
+
+	return
+}
+
+// 折叠全半角与大小写（不去除任何字符，折叠后的字符与原始字符一一对应；用于正则表达式的匹配）
+// originalRuneList：原始字符列表
+// 返回值：
+// 折叠后的字符串
+func fold(originalRuneList []rune) string {
+	foldedRuneList := make([]rune, len(originalRuneList))
+	for index, item := range originalRuneList {
+		// 只使用折叠为单个字符的结果，以保持一一对应
+		if folded := []rune(width.Fold.String(string(item))); len(folded) == 1 {
+			item = folded[0]
+		}
+
+		foldedRuneList[index] = unicode.ToLower(item)
+	}
+
+	return string(foldedRuneList)
+}
+
+// 规范化词汇（用于构造DFA）
+// word：词汇
+// 返回值：
+// 规范化后的词汇
+func normalizeWord(word string) string {
+	normalizedRuneList, _ := normalize(word)
+	return string(normalizedRuneList)
+}
+
+// 规范化词汇列表（去除规范化后为空的词汇）
+// wordList：词汇列表
+// 返回值：
+// 规范化后的词汇列表
+func normalizeWordList(wordList []string) []string {
+	normalizedWordList := make([]string, 0, len(wordList))
+	for _, word := range wordList {
+		if normalizedWord := normalizeWord(word); normalizedWord != "" {
+			normalizedWordList = append(normalizedWordList, normalizedWord)
+		}
+	}
+
+	return normalizedWordList
+}
+
+// 是否为填充字符（匹配时忽略）
+// r：字符
+// 返回值：
+// 是否为填充字符
+func isFiller(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.In(r, unicode.Cc, unicode.Cf, unicode.Mn)
+}
+
+// 规范化消息（供重复消息检测等场景比较消息内容使用）
+// message：消息
+// 返回值：
+// 规范化后的消息
+func NormalizeMessage(message string) string {
+	return normalizeWord(message)
+}
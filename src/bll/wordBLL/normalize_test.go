@@ -0,0 +1,82 @@
+package wordBLL
+
+import (
+	"testing"
+)
+
+// 设置测试用的形近字映射，并在测试结束后恢复
+func setTestHomoglyphMap(t *testing.T, testHomoglyphMap map[rune]rune) {
+	oldHomoglyphMap := homoglyphMap
+	homoglyphMap = testHomoglyphMap
+	t.Cleanup(func() {
+		homoglyphMap = oldHomoglyphMap
+	})
+}
+
+func TestNormalizeWord(t *testing.T) {
+	setTestHomoglyphMap(t, map[rune]rune{
+		'國': '国',
+		'0': 'o',
+	})
+
+	testCaseList := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"全角字母数字", "ＡＢＣ１２３", "abc123"},
+		{"半角片假名", "ｶﾞﾁｬ", "ガチャ"},
+		{"大小写", "HeLLo WoRLD", "helloworld"},
+		{"形近字", "中國", "中国"},
+		{"形近数字", "g00d", "good"},
+		{"空格与标点", "f u-c.k", "fuck"},
+		{"全角空格与标点", "外\u3000挂！", "外挂"},
+		{"零宽字符", "外\u200b挂", "外挂"},
+		{"符号", "外★挂♥", "外挂"},
+		{"NFKC圈字符", "①ⓑ", "1b"},
+		{"NFKC连字", "ﬁne", "fine"},
+		{"NFKC组合字符", "cafe\u0301", "café"},
+		{"NFKC预组合字符", "café", "café"},
+		{"NFKC带括号的字符", "㈱", "株"},
+		{"空字符串", "", ""},
+		{"只有填充字符", " ,.!？", ""},
+	}
+
+	for _, item := range testCaseList {
+		t.Run(item.name, func(t *testing.T) {
+			if actual := normalizeWord(item.input); actual != item.expected {
+				t.Errorf("normalizeWord(%q)=%q，期望为：%q", item.input, actual, item.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeOriginalRange(t *testing.T) {
+	setTestHomoglyphMap(t, map[rune]rune{})
+
+	testCaseList := []struct {
+		name     string
+		input    string
+		expected []originalRange
+	}{
+		{"忽略的填充字符", "a b", []originalRange{{0, 0}, {2, 2}}},
+		{"全角字符", "Ａｂ", []originalRange{{0, 0}, {1, 1}}},
+		{"组合字符对应多个原始字符", "e\u0301x", []originalRange{{0, 1}, {2, 2}}},
+		{"一个原始字符分解为多个字符", "ﬁx", []originalRange{{0, 0}, {0, 0}, {1, 1}}},
+	}
+
+	for _, item := range testCaseList {
+		t.Run(item.name, func(t *testing.T) {
+			_, actual := normalize(item.input)
+			if len(actual) != len(item.expected) {
+				t.Fatalf("normalize(%q)的原始范围为：%v，期望为：%v", item.input, actual, item.expected)
+			}
+
+			for index := range actual {
+				if actual[index] != item.expected[index] {
+					t.Fatalf("normalize(%q)的原始范围为：%v，期望为：%v", item.input, actual, item.expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package wordBLL
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/goutil/dfaUtil"
+)
+
+const (
+	// 白名单词汇在规范化字符串中的占位字符（规范化后的词汇不包含空白，故DFA不会跨越白名单匹配）
+	con_WhitelistRune = ' '
+)
+
+// 词汇规则集合（普通词汇、正则表达式、白名单共同生效）
+type wordRuleSet struct {
+	// 原始的规则列表（形近字映射变化后用于重新构造）
+	ruleList []*model.WordRule
+
+	// 普通词汇的数量，及由规范化后的普通词汇构造的DFAUtil对象
+	literalCount int
+	dfaObj       *dfaUtil.DFAUtil
+
+	// 正则表达式列表
+	regexpList []*regexp.Regexp
+
+	// 规范化后的白名单列表，及其集合
+	whitelist    []string
+	whitelistMap map[string]bool
+}
+
+// 新建词汇规则集合
+// ruleList：规则列表
+// 返回值：
+// 词汇规则集合
+// 错误对象（正则表达式不正确或规则类型未定义）
+func newWordRuleSet(ruleList []*model.WordRule) (*wordRuleSet, error) {
+	literalList := make([]string, 0, len(ruleList))
+	ruleSetObj := &wordRuleSet{
+		ruleList:     ruleList,
+		regexpList:   make([]*regexp.Regexp, 0, 8),
+		whitelist:    make([]string, 0, 8),
+		whitelistMap: make(map[string]bool, 8),
+	}
+
+	for _, item := range ruleList {
+		switch item.RuleType {
+		case model.Con_WordRule_Literal:
+			literalList = append(literalList, item.Word)
+		case model.Con_WordRule_Regexp:
+			regexpObj, err := regexp.Compile(item.Word)
+			if err != nil {
+				return nil, fmt.Errorf("正则表达式:%s不正确，错误信息为：%s", item.Word, err)
+			}
+			ruleSetObj.regexpList = append(ruleSetObj.regexpList, regexpObj)
+		case model.Con_WordRule_Whitelist:
+			if word := normalizeWord(item.Word); word != "" && !ruleSetObj.whitelistMap[word] {
+				ruleSetObj.whitelist = append(ruleSetObj.whitelist, word)
+				ruleSetObj.whitelistMap[word] = true
+			}
+		default:
+			return nil, fmt.Errorf("词汇:%s的RuleType:%d未定义", item.Word, item.RuleType)
+		}
+	}
+
+	literalList = normalizeWordList(literalList)
+	ruleSetObj.literalCount = len(literalList)
+	ruleSetObj.dfaObj = dfaUtil.NewDFAUtil(literalList)
+
+	return ruleSetObj, nil
+}
+
+// 是否为空（没有可以匹配的规则）
+func (s *wordRuleSet) isEmpty() bool {
+	return s.literalCount == 0 && len(s.regexpList) == 0
+}
+
+// 是否包含匹配的内容
+// input：输入字符串
+// 返回值：
+// 是否包含
+func (s *wordRuleSet) isMatch(input string) bool {
+	if s.isEmpty() {
+		return false
+	}
+
+	_, matchedList := s.match(input)
+	for _, matched := range matchedList {
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 将匹配的内容替换为指定字符
+// input：输入字符串
+// maskRune：替换字符
+// 返回值：
+// 替换后的字符串
+func (s *wordRuleSet) mask(input string, maskRune rune) string {
+	if s.isEmpty() {
+		return input
+	}
+
+	originalRuneList, matchedList := s.match(input)
+	for index, matched := range matchedList {
+		if matched {
+			originalRuneList[index] = maskRune
+		}
+	}
+
+	return string(originalRuneList)
+}
+
+// 匹配输入字符串
+// input：输入字符串
+// 返回值：
+// 原始字符列表
+// 每个原始字符是否被匹配
+func (s *wordRuleSet) match(input string) (originalRuneList []rune, matchedList []bool) {
+	originalRuneList = []rune(input)
+	matchedList = make([]bool, len(originalRuneList))
+
+	normalizedRuneList, originalRangeList := normalize(input)
+	normalizedRuneList = s.replaceWhitelist(normalizedRuneList)
+
+	// 将规范化字符串中[start, end)的匹配映射回原始字符串（其间被忽略的填充字符也一并视为匹配）
+	markNormalized := func(start, end int) {
+		for index := originalRangeList[start].start; index <= originalRangeList[end-1].end; index++ {
+			matchedList[index] = true
+		}
+	}
+
+	// 普通词汇：DFA替换后被替换为*的字符即为匹配的字符（规范化后的字符不包含*）
+	if s.literalCount > 0 {
+		maskedRuneList := []rune(s.dfaObj.HandleWord(string(normalizedRuneList), con_MaskRune))
+		if len(maskedRuneList) == len(normalizedRuneList) {
+			for start := 0; start < len(maskedRuneList); start++ {
+				if maskedRuneList[start] != con_MaskRune {
+					continue
+				}
+
+				end := start + 1
+				for end < len(maskedRuneList) && maskedRuneList[end] == con_MaskRune {
+					end++
+				}
+
+				markNormalized(start, end)
+				start = end
+			}
+		}
+	}
+
+	// 正则表达式：分别在原始字符串和全半角、大小写折叠后的字符串上匹配（两者的字符一一对应）；
+	// 不在规范化字符串上匹配，因为去除空白、标点后，如“100, 200, 300”会变为“100200300”，从而被QQ号、手机号等模式误匹配
+	if len(s.regexpList) > 0 {
+		whitelistedList := s.getWhitelistedList(len(originalRuneList), normalizedRuneList, originalRangeList)
+		markRegexp := func(target string) {
+			for _, regexpObj := range s.regexpList {
+				for _, item := range regexpObj.FindAllStringIndex(target, -1) {
+					// 只标记不在白名单词汇中的字符
+					start := runeIndex(target, item[0])
+					end := start + utf8.RuneCountInString(target[item[0]:item[1]])
+					for index := start; index < end; index++ {
+						if !whitelistedList[index] {
+							matchedList[index] = true
+						}
+					}
+				}
+			}
+		}
+
+		markRegexp(input)
+		if foldedString := fold(originalRuneList); foldedString != input {
+			markRegexp(foldedString)
+		}
+	}
+
+	return
+}
+
+// 获取原始字符串中属于白名单词汇的字符（包括白名单词汇中间被忽略的填充字符）
+// originalRuneCount：原始字符的数量
+// normalizedRuneList：已替换白名单词汇的规范化字符列表
+// originalRangeList：规范化后的每个字符对应的原始字符的范围
+// 返回值：
+// 每个原始字符是否属于白名单词汇
+func (s *wordRuleSet) getWhitelistedList(originalRuneCount int, normalizedRuneList []rune, originalRangeList []originalRange) []bool {
+	whitelistedList := make([]bool, originalRuneCount)
+	for start := 0; start < len(normalizedRuneList); start++ {
+		if normalizedRuneList[start] != con_WhitelistRune {
+			continue
+		}
+
+		end := start + 1
+		for end < len(normalizedRuneList) && normalizedRuneList[end] == con_WhitelistRune {
+			end++
+		}
+
+		for index := originalRangeList[start].start; index <= originalRangeList[end-1].end; index++ {
+			whitelistedList[index] = true
+		}
+		start = end
+	}
+
+	return whitelistedList
+}
+
+// 将规范化字符列表中的白名单词汇替换为占位字符（长度不变，以保持与原始字符的对应关系）
+// normalizedRuneList：规范化字符列表
+// 返回值：
+// 替换后的字符列表
+func (s *wordRuleSet) replaceWhitelist(normalizedRuneList []rune) []rune {
+	if len(s.whitelist) == 0 {
+		return normalizedRuneList
+	}
+
+	resultList := make([]rune, len(normalizedRuneList))
+	copy(resultList, normalizedRuneList)
+
+	for _, word := range s.whitelist {
+		wordRuneList := []rune(word)
+		for start := 0; start+len(wordRuneList) <= len(normalizedRuneList); start++ {
+			if string(normalizedRuneList[start:start+len(wordRuneList)]) != word {
+				continue
+			}
+
+			for index := start; index < start+len(wordRuneList); index++ {
+				resultList[index] = con_WhitelistRune
+			}
+		}
+	}
+
+	return resultList
+}
+
+// 将字节位置转换为字符位置
+// input：字符串
+// byteIndex：字节位置
+// 返回值：
+// 字符位置
+func runeIndex(input string, byteIndex int) int {
+	return len([]rune(input[:byteIndex]))
+}
@@ -0,0 +1,111 @@
+package wordBLL
+
+import (
+	"testing"
+
+	"github.com/Jordanzuo/ChatServer/src/model"
+)
+
+// 设置测试用的屏蔽词、敏感词规则（两者使用相同的规则），并在测试结束后恢复
+func setTestRuleList(t *testing.T, ruleList []*model.WordRule) {
+	oldForbidRuleSetObj, oldSensitiveRuleSetObj := forbidRuleSetObj, sensitiveRuleSetObj
+	t.Cleanup(func() {
+		forbidRuleSetObj, sensitiveRuleSetObj = oldForbidRuleSetObj, oldSensitiveRuleSetObj
+	})
+
+	if err := buildForbidRuleSet(ruleList); err != nil {
+		t.Fatal(err)
+	}
+	if err := buildSensitiveRuleSet(ruleList); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 规则匹配的测试用例
+type ruleTestCase struct {
+	// 名称
+	name string
+
+	// 输入字符串
+	input string
+
+	// 是否包含屏蔽词
+	isForbidden bool
+
+	// 处理敏感词后的字符串
+	masked string
+}
+
+// 同时检查屏蔽词判断与敏感词替换
+func runRuleTestCaseList(t *testing.T, testCaseList []ruleTestCase) {
+	for _, item := range testCaseList {
+		t.Run(item.name, func(t *testing.T) {
+			if actual := IfContainsForbidWords(item.input); actual != item.isForbidden {
+				t.Errorf("IfContainsForbidWords(%q)=%v，期望为：%v", item.input, actual, item.isForbidden)
+			}
+
+			if actual := HandleSensitiveWords(item.input); actual != item.masked {
+				t.Errorf("HandleSensitiveWords(%q)=%q，期望为：%q", item.input, actual, item.masked)
+			}
+		})
+	}
+}
+
+func TestLiteralRule(t *testing.T) {
+	setTestHomoglyphMap(t, map[rune]rune{
+		'卦': '挂',
+		'0': 'o',
+	})
+	setTestRuleList(t, []*model.WordRule{
+		model.NewWordRule("外挂", model.Con_WordRule_Literal),
+		model.NewWordRule("badword", model.Con_WordRule_Literal),
+		model.NewWordRule("ガチャ", model.Con_WordRule_Literal),
+		model.NewWordRule("café", model.Con_WordRule_Literal),
+		model.NewWordRule("窗外挂着", model.Con_WordRule_Whitelist),
+	})
+
+	runRuleTestCaseList(t, []ruleTestCase{
+		{"不包含", "今天天气不错", false, "今天天气不错"},
+		{"原样", "你要外挂吗", true, "你要**吗"},
+		{"全角字符", "ＢＡＤＷＯＲＤ", true, "*******"},
+		{"半角片假名", "ｶﾞﾁｬ引く", true, "****引く"},
+		{"大小写", "BadWord!", true, "*******!"},
+		{"形近字", "外卦", true, "**"},
+		{"形近数字", "badw0rd", true, "*******"},
+		{"插入空格", "外 挂", true, "***"},
+		{"插入全角空格", "外\u3000挂", true, "***"},
+		{"插入标点", "b.a.d-w_o*r#d", true, "*************"},
+		{"插入零宽字符", "外\u200b挂", true, "***"},
+		{"插入符号", "外★挂", true, "***"},
+		{"NFKC圈字符", "ⓑadword", true, "*******"},
+		{"NFKC组合字符", "cafe\u0301", true, "*****"},
+		{"NFKC预组合字符", "café", true, "****"},
+		{"白名单", "窗外挂着灯笼", false, "窗外挂着灯笼"},
+		{"白名单插入空格", "窗外 挂着灯笼", false, "窗外 挂着灯笼"},
+		{"白名单之外仍然匹配", "窗外挂着外挂", true, "窗外挂着**"},
+		{"前后的填充字符不替换", " 外挂 ", true, " ** "},
+	})
+}
+
+func TestRegexpRule(t *testing.T) {
+	setTestHomoglyphMap(t, map[rune]rune{})
+	setTestRuleList(t, []*model.WordRule{
+		model.NewWordRule(`[1-9][0-9]{5,10}`, model.Con_WordRule_Regexp),
+		model.NewWordRule(`qq`, model.Con_WordRule_Regexp),
+		model.NewWordRule(`www\.[a-z0-9]+\.com`, model.Con_WordRule_Regexp),
+		model.NewWordRule("QQ音乐", model.Con_WordRule_Whitelist),
+	})
+
+	runRuleTestCaseList(t, []ruleTestCase{
+		{"不包含", "今天天气不错", false, "今天天气不错"},
+		{"QQ号", "加我123456789", true, "加我*********"},
+		{"全角QQ号", "加我１２３４５６７８９", true, "加我*********"},
+		{"以空格分隔的数字不拼接", "100, 200, 300", false, "100, 200, 300"},
+		{"以标点分隔的数字不拼接", "攻击力1000，防御力2000", false, "攻击力1000，防御力2000"},
+		{"依赖标点的网址", "www.example.com", true, "***************"},
+		{"全角大写的网址", "ＷＷＷ.EXAMPLE.ＣＯＭ", true, "***************"},
+		{"大小写折叠", "加我QQ", true, "加我**"},
+		{"白名单", "听QQ音乐", false, "听QQ音乐"},
+		{"白名单之外仍然匹配", "听QQ音乐，加我qq", true, "听QQ音乐，加我**"},
+	})
+}
@@ -5,13 +5,17 @@ import (
 
 	"github.com/Jordanzuo/ChatServer/src/bll/reloadBLL"
 	"github.com/Jordanzuo/ChatServer/src/dal/wordDAL"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/goutil/debugUtil"
-	"github.com/Jordanzuo/goutil/dfaUtil"
+)
+
+const (
+	// 敏感词的替换字符
+	con_MaskRune = '*'
 )
 
 var (
-	sensitiveWordList []string = make([]string, 0, 1024)
-	sensitiveDFAObj   *dfaUtil.DFAUtil
+	sensitiveRuleSetObj *wordRuleSet
 )
 
 func init() {
@@ -23,28 +27,36 @@ func init() {
 	reloadBLL.RegisterReloadFunc("Sensitive", ReloadSensitive)
 }
 
-// 重新加载敏感词列表
+// 重新加载敏感词列表（加载失败时继续使用原有的规则）
 func ReloadSensitive() error {
-	var err error
-	if sensitiveWordList, err = wordDAL.InitSensitive(); err != nil {
+	sensitiveRuleList, err := wordDAL.InitSensitive()
+	if err != nil {
 		return err
 	}
 
-	debugUtil.Printf("SensitiveWordList:%v\n", sensitiveWordList)
+	debugUtil.Printf("SensitiveRuleList:%v\n", sensitiveRuleList)
 
-	// 构造DFAUtil对象
-	sensitiveDFAObj = dfaUtil.NewDFAUtil(sensitiveWordList)
+	return buildSensitiveRuleSet(sensitiveRuleList)
+}
+
+// 构造敏感词规则集合
+// ruleList：规则列表
+// 返回值：
+// 错误对象
+func buildSensitiveRuleSet(ruleList []*model.WordRule) error {
+	ruleSetObj, err := newWordRuleSet(ruleList)
+	if err != nil {
+		return err
+	}
+
+	sensitiveRuleSetObj = ruleSetObj
 
 	return nil
 }
 
-// 处理屏蔽词汇
+// 处理屏蔽词汇（在规范化后的字符串上匹配，再将匹配的位置映射回原始字符串进行替换；白名单中的词汇不替换）
 // 输入字符串
 // 处理屏蔽词汇后的字符串
 func HandleSensitiveWords(input string) string {
-	if len(sensitiveWordList) == 0 {
-		return input
-	}
-
-	return sensitiveDFAObj.HandleWord(input, '*')
+	return sensitiveRuleSetObj.mask(input, con_MaskRune)
 }
@@ -18,6 +18,51 @@ var (
 
 	// 聊天服务器公网地址
 	ChatServerPublicAddress string
+
+	// WebSocket监听地址（为空则不启动WebSocket服务器）
+	WebSocketListenAddress string
+
+	// WebSocket允许的来源（多个以逗号分隔；为空则只允许同源，*表示允许所有来源）
+	WebSocketAllowedOrigins string
+
+	// TLS证书文件路径（为空则不启用TLS）
+	TLSCertFile string
+
+	// TLS私钥文件路径
+	TLSKeyFile string
+
+	// 最低TLS版本（1.0、1.1、1.2、1.3）
+	TLSMinVersion string
+
+	// 客户端CA证书文件路径（不为空则要求并验证客户端证书）
+	TLSClientCAFile string
+
+	// 每个客户端待发送数据的最大数量（超过则丢弃数据，<=0表示不限制）
+	MaxSendQueueLength int
+
+	// 每个客户端待发送数据的高水位（<=0表示不检查）
+	SendQueueHighWaterMark int
+
+	// 待发送数据持续超过高水位的最长时间（单位：秒，超过则断开连接）
+	SlowClientTimeout int
+
+	// 指标服务器监听地址（为空则不启动指标服务器）
+	MetricsListenAddress string
+
+	// 登陆成功后自动发送的每个频道的聊天记录数量（<=0表示不发送）
+	LoginHistoryCount int
+
+	// 每个玩家保留的离线私聊消息的最大数量（<=0表示不保存离线私聊消息）
+	OfflineMessageMaxCount int
+
+	// 离线私聊消息的保留天数
+	OfflineMessageExpireDays int
+
+	// 发送者可以撤回消息的时间窗口（单位：秒，<=0表示不允许撤回）
+	RecallWindowSeconds int
+
+	// 是否经Center转发信封（消息Id、结构化内容、提及、回执等扩展信息）；须在所有ChatServer升级完成后才能启用
+	ChatEnvelopeEnabled bool
 )
 
 func init() {
@@ -50,10 +95,78 @@ func init() {
 	ChatServerPublicAddress, err = configUtil.ReadStringJsonValue(config, "ChatServerPublicAddress")
 	checkError(err)
 
+	// 解析WebSocketListenAddress
+	WebSocketListenAddress, err = configUtil.ReadStringJsonValue(config, "WebSocketListenAddress")
+	checkError(err)
+
+	WebSocketAllowedOrigins, err = configUtil.ReadStringJsonValue(config, "WebSocketAllowedOrigins")
+	checkError(err)
+
+	// 解析TLS配置
+	TLSCertFile, err = configUtil.ReadStringJsonValue(config, "TLSCertFile")
+	checkError(err)
+
+	TLSKeyFile, err = configUtil.ReadStringJsonValue(config, "TLSKeyFile")
+	checkError(err)
+
+	TLSMinVersion, err = configUtil.ReadStringJsonValue(config, "TLSMinVersion")
+	checkError(err)
+
+	TLSClientCAFile, err = configUtil.ReadStringJsonValue(config, "TLSClientCAFile")
+	checkError(err)
+
+	// 解析待发送数据的限制
+	MaxSendQueueLength, err = configUtil.ReadIntJsonValue(config, "MaxSendQueueLength")
+	checkError(err)
+
+	SendQueueHighWaterMark, err = configUtil.ReadIntJsonValue(config, "SendQueueHighWaterMark")
+	checkError(err)
+
+	SlowClientTimeout, err = configUtil.ReadIntJsonValue(config, "SlowClientTimeout")
+	checkError(err)
+
+	// 解析MetricsListenAddress
+	MetricsListenAddress, err = configUtil.ReadStringJsonValue(config, "MetricsListenAddress")
+	checkError(err)
+
+	// 解析LoginHistoryCount
+	LoginHistoryCount, err = configUtil.ReadIntJsonValue(config, "LoginHistoryCount")
+	checkError(err)
+
+	// 解析离线私聊消息的限制
+	OfflineMessageMaxCount, err = configUtil.ReadIntJsonValue(config, "OfflineMessageMaxCount")
+	checkError(err)
+
+	OfflineMessageExpireDays, err = configUtil.ReadIntJsonValue(config, "OfflineMessageExpireDays")
+	checkError(err)
+
+	// 解析RecallWindowSeconds
+	RecallWindowSeconds, err = configUtil.ReadIntJsonValue(config, "RecallWindowSeconds")
+	checkError(err)
+
+	// 解析ChatEnvelopeEnabled
+	ChatEnvelopeEnabled, err = configUtil.ReadBoolJsonValue(config, "ChatEnvelopeEnabled")
+	checkError(err)
+
 	debugUtil.Println("DEBUG:", debug)
 	debugUtil.Println("DBConnection:", DBConnection)
 	debugUtil.Println("ChatServerListenAddress:", ChatServerListenAddress)
 	debugUtil.Println("ChatServerPublicAddress:", ChatServerPublicAddress)
+	debugUtil.Println("WebSocketListenAddress:", WebSocketListenAddress)
+	debugUtil.Println("WebSocketAllowedOrigins:", WebSocketAllowedOrigins)
+	debugUtil.Println("TLSCertFile:", TLSCertFile)
+	debugUtil.Println("TLSKeyFile:", TLSKeyFile)
+	debugUtil.Println("TLSMinVersion:", TLSMinVersion)
+	debugUtil.Println("TLSClientCAFile:", TLSClientCAFile)
+	debugUtil.Println("MaxSendQueueLength:", MaxSendQueueLength)
+	debugUtil.Println("SendQueueHighWaterMark:", SendQueueHighWaterMark)
+	debugUtil.Println("SlowClientTimeout:", SlowClientTimeout)
+	debugUtil.Println("MetricsListenAddress:", MetricsListenAddress)
+	debugUtil.Println("LoginHistoryCount:", LoginHistoryCount)
+	debugUtil.Println("OfflineMessageMaxCount:", OfflineMessageMaxCount)
+	debugUtil.Println("OfflineMessageExpireDays:", OfflineMessageExpireDays)
+	debugUtil.Println("RecallWindowSeconds:", RecallWindowSeconds)
+	debugUtil.Println("ChatEnvelopeEnabled:", ChatEnvelopeEnabled)
 }
 
 func checkError(err error) {
@@ -0,0 +1,165 @@
+package chatDAL
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 保存聊天记录（保存成功后设置其Id）
+// historyObj：聊天记录
+// 返回值：
+// 错误对象
+func InsertHistory(historyObj *model.ChatHistory) error {
+	command := `INSERT INTO 
+                chat_history(MessageId, ConversationKey, ChannelType, ServerGroupId, PlayerId, Name, PartnerId, ServerId, UnionId, ExtraMsg, ToPlayerId, Message, Content, SendTime)
+            VALUES
+                (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+    `
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	result, err := stmt.Exec(historyObj.MessageId, historyObj.ConversationKey, historyObj.ChannelType, historyObj.ServerGroupId, historyObj.PlayerId, historyObj.Name,
+		historyObj.PartnerId, historyObj.ServerId, historyObj.UnionId, historyObj.ExtraMsg, historyObj.ToPlayerId, historyObj.Message, historyObj.Content.Encode(), historyObj.SendTime)
+	if err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	if historyObj.Id, err = result.LastInsertId(); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 获取会话最近的聊天记录
+// conversationKey：会话的键
+// count：数量
+// 返回值：
+// 聊天记录列表（按发送时间从早到晚）
+// 错误对象
+func GetHistoryList(conversationKey string, count int) (historyList []*model.ChatHistory, err error) {
+	command := `SELECT 
+					Id, MessageId, ChannelType, ServerGroupId, PlayerId, Name, PartnerId, ServerId, UnionId, ExtraMsg, ToPlayerId, Message, Content, SendTime
+				FROM 
+					chat_history
+				WHERE 
+					ConversationKey = ? AND IsDeleted = 0
+				ORDER BY Id DESC 
+				LIMIT ?;`
+
+	rows, err := dal.GetDB().Query(command, conversationKey, count)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var messageId string
+		var _channelType int
+		var serverGroupId int
+		var playerId string
+		var name string
+		var partnerId int
+		var serverId int
+		var unionId string
+		var extraMsg string
+		var toPlayerId string
+		var message string
+		var content string
+		var sendTime time.Time
+		if err = rows.Scan(&id, &messageId, &_channelType, &serverGroupId, &playerId, &name, &partnerId, &serverId, &unionId, &extraMsg, &toPlayerId, &message, &content, &sendTime); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		historyList = append(historyList, &model.ChatHistory{
+			Id:              id,
+			MessageId:       messageId,
+			ConversationKey: conversationKey,
+			ChannelType:     channelType.ChannelType(_channelType),
+			ServerGroupId:   serverGroupId,
+			PlayerId:        playerId,
+			Name:            name,
+			PartnerId:       partnerId,
+			ServerId:        serverId,
+			UnionId:         unionId,
+			ExtraMsg:        extraMsg,
+			ToPlayerId:      toPlayerId,
+			Message:         message,
+			Content:         model.DecodeMessageContent(content),
+			SendTime:        sendTime,
+		})
+	}
+
+	// 查询时按Id倒序，以便取最近的记录；返回时调整为从早到晚
+	for i, j := 0, len(historyList)-1; i < j; i, j = i+1, j-1 {
+		historyList[i], historyList[j] = historyList[j], historyList[i]
+	}
+
+	return
+}
+
+// 根据消息Id获取聊天记录
+// messageId：消息Id
+// 返回值：
+// 聊天记录
+// 是否存在
+// 错误对象
+func GetHistoryByMessageId(messageId string) (historyObj *model.ChatHistory, exists bool, err error) {
+	command := `SELECT 
+					Id, ConversationKey, ChannelType, ServerGroupId, PlayerId, Name, PartnerId, ServerId, UnionId, ExtraMsg, ToPlayerId, Message, Content, SendTime, IsDeleted
+				FROM 
+					chat_history
+				WHERE 
+					MessageId = ?;`
+
+	historyObj = &model.ChatHistory{MessageId: messageId}
+	var _channelType int
+	var content string
+	if err = dal.GetDB().QueryRow(command, messageId).Scan(&historyObj.Id, &historyObj.ConversationKey, &_channelType, &historyObj.ServerGroupId, &historyObj.PlayerId, &historyObj.Name,
+		&historyObj.PartnerId, &historyObj.ServerId, &historyObj.UnionId, &historyObj.ExtraMsg, &historyObj.ToPlayerId, &historyObj.Message, &content, &historyObj.SendTime, &historyObj.IsDeleted); err != nil {
+		historyObj = nil
+		if err == sql.ErrNoRows {
+			// 重置err，使其为nil；因为这代表的是没有查找到数据，而不是真正的错误
+			err = nil
+			return
+		} else {
+			dal.WriteScanError(command, err)
+			return
+		}
+	}
+
+	historyObj.ChannelType = channelType.ChannelType(_channelType)
+	historyObj.Content = model.DecodeMessageContent(content)
+	exists = true
+
+	return
+}
+
+// 删除聊天记录（撤回、删除消息时调用；只标记为已删除，以便其它ChatServer仍能查询到其会话信息）
+// messageId：消息Id
+// 返回值：
+// 错误对象
+func DeleteHistory(messageId string) error {
+	command := "UPDATE chat_history SET IsDeleted = 1 WHERE MessageId = ?;"
+	if _, err := dal.GetDB().Exec(command, messageId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
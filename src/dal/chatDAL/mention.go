@@ -0,0 +1,123 @@
+package chatDAL
+
+import (
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 保存未读的提及（消息内容保存在聊天记录中，此处只保存消息Id）
+// messageId：消息Id
+// playerIdList：被提及的玩家Id列表
+// sendTime：发送时间
+// 返回值：
+// 错误对象
+func InsertUnreadMention(messageId string, playerIdList []string, sendTime time.Time) error {
+	command := `INSERT INTO
+                unread_mention(PlayerId, MessageId, SendTime)
+            VALUES
+                (?, ?, ?);
+    `
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	for _, playerId := range playerIdList {
+		if _, err = stmt.Exec(playerId, messageId, sendTime); err != nil {
+			dal.WriteExecError(command, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 删除一条未读的提及（被提及的玩家在其它服务器在线并已收到时调用）
+// messageId：消息Id
+// playerId：被提及的玩家Id
+// 返回值：
+// 错误对象
+func DeleteUnreadMention(messageId, playerId string) error {
+	command := "DELETE FROM unread_mention WHERE MessageId = ? AND PlayerId = ?;"
+	if _, err := dal.GetDB().Exec(command, messageId, playerId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 取出玩家未过期的未读提及（取出后即从数据库中删除；已撤回、删除的消息不会取出）
+// playerId：被提及的玩家Id
+// expireTime：早于该时间发送的消息视为已过期
+// maxCount：取出的最大数量（只取最近的）
+// 返回值：
+// 提及玩家的聊天记录列表（按发送顺序）
+// 错误对象
+func TakeUnreadMentionList(playerId string, expireTime time.Time, maxCount int) (historyList []*model.ChatHistory, err error) {
+	command := `SELECT
+					h.Id, h.MessageId, h.ConversationKey, h.ChannelType, h.ServerGroupId, h.PlayerId, h.Name, h.PartnerId, h.ServerId, h.UnionId, h.ExtraMsg, h.ToPlayerId, h.Message, h.Content, h.SendTime
+				FROM
+					unread_mention m INNER JOIN chat_history h ON h.MessageId = m.MessageId
+				WHERE
+					m.PlayerId = ? AND m.SendTime >= ? AND h.IsDeleted = 0
+				ORDER BY m.Id DESC
+				LIMIT ?;`
+
+	rows, err := dal.GetDB().Query(command, playerId, expireTime, maxCount)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		historyObj := new(model.ChatHistory)
+		var _channelType int
+		var content string
+		if err = rows.Scan(&historyObj.Id, &historyObj.MessageId, &historyObj.ConversationKey, &_channelType, &historyObj.ServerGroupId, &historyObj.PlayerId, &historyObj.Name,
+			&historyObj.PartnerId, &historyObj.ServerId, &historyObj.UnionId, &historyObj.ExtraMsg, &historyObj.ToPlayerId, &historyObj.Message, &content, &historyObj.SendTime); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		historyObj.ChannelType = channelType.ChannelType(_channelType)
+		historyObj.Content = model.DecodeMessageContent(content)
+		historyList = append(historyList, historyObj)
+	}
+
+	// 查询时是倒序的，调整为按发送顺序
+	for i, j := 0, len(historyList)-1; i < j; i, j = i+1, j-1 {
+		historyList[i], historyList[j] = historyList[j], historyList[i]
+	}
+
+	// 删除玩家所有的未读提及（包括超过数量上限、已过期和已撤回的）
+	command = "DELETE FROM unread_mention WHERE PlayerId = ?;"
+	if _, err = dal.GetDB().Exec(command, playerId); err != nil {
+		dal.WriteExecError(command, err)
+		return
+	}
+
+	return
+}
+
+// 删除已过期的未读提及
+// expireTime：早于该时间发送的消息视为已过期
+// 返回值：
+// 错误对象
+func DeleteExpiredUnreadMention(expireTime time.Time) error {
+	command := "DELETE FROM unread_mention WHERE SendTime < ?;"
+	if _, err := dal.GetDB().Exec(command, expireTime); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package chatDAL
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 保存离线私聊消息，并删除超过数量上限的最早的消息
+// messageObj：私聊消息
+// maxCount：每个玩家保留的最大数量
+// 返回值：
+// 错误对象
+func InsertOfflineMessage(messageObj *model.ChatHistory, maxCount int) error {
+	command := `INSERT INTO 
+                offline_message(MessageId, ToPlayerId, PlayerId, Name, PartnerId, ServerId, UnionId, ExtraMsg, Message, Content, SendTime)
+            VALUES
+                (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+    `
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(messageObj.MessageId, messageObj.ToPlayerId, messageObj.PlayerId, messageObj.Name, messageObj.PartnerId, messageObj.ServerId,
+		messageObj.UnionId, messageObj.ExtraMsg, messageObj.Message, messageObj.Content.Encode(), messageObj.SendTime); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	// 删除超过数量上限的最早的消息（MySQL不支持在子查询中直接使用LIMIT，故嵌套一层）
+	command = `DELETE FROM offline_message 
+				WHERE ToPlayerId = ? AND Id <= (
+					SELECT Id FROM (
+						SELECT Id FROM offline_message WHERE ToPlayerId = ? ORDER BY Id DESC LIMIT 1 OFFSET ?
+					) AS t
+				);`
+	if _, err = dal.GetDB().Exec(command, messageObj.ToPlayerId, messageObj.ToPlayerId, maxCount); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 删除一条离线私聊消息（目标玩家在其它服务器在线并已收到时调用）
+// messageId：消息Id
+// 返回值：
+// 错误对象
+func DeleteOfflineMessage(messageId string) error {
+	command := "DELETE FROM offline_message WHERE MessageId = ?;"
+	if _, err := dal.GetDB().Exec(command, messageId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 获取玩家未过期的离线私聊消息（推送成功后再调用DeleteOfflineMessageList删除）
+// toPlayerId：目标玩家Id
+// expireTime：早于该时间发送的消息视为已过期
+// 返回值：
+// 私聊消息列表（按发送顺序）
+// 错误对象
+func GetOfflineMessageList(toPlayerId string, expireTime time.Time) (messageList []*model.ChatHistory, err error) {
+	command := `SELECT 
+					Id, MessageId, PlayerId, Name, PartnerId, ServerId, UnionId, ExtraMsg, Message, Content, SendTime
+				FROM 
+					offline_message
+				WHERE 
+					ToPlayerId = ? AND SendTime >= ?
+				ORDER BY Id;`
+
+	rows, err := dal.GetDB().Query(command, toPlayerId, expireTime)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var messageId string
+		var playerId string
+		var name string
+		var partnerId int
+		var serverId int
+		var unionId string
+		var extraMsg string
+		var message string
+		var content string
+		var sendTime time.Time
+		if err = rows.Scan(&id, &messageId, &playerId, &name, &partnerId, &serverId, &unionId, &extraMsg, &message, &content, &sendTime); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		messageList = append(messageList, &model.ChatHistory{
+			Id:          id,
+			MessageId:   messageId,
+			ChannelType: channelType.Private,
+			PlayerId:    playerId,
+			Name:        name,
+			PartnerId:   partnerId,
+			ServerId:    serverId,
+			UnionId:     unionId,
+			ExtraMsg:    extraMsg,
+			ToPlayerId:  toPlayerId,
+			Message:     message,
+			Content:     model.DecodeMessageContent(content),
+			SendTime:    sendTime,
+		})
+	}
+
+	return
+}
+
+// 删除玩家已收到的离线私聊消息
+// toPlayerId：目标玩家Id
+// idList：离线私聊消息的Id列表
+// 返回值：
+// 错误对象
+func DeleteOfflineMessageList(toPlayerId string, idList []int64) error {
+	if len(idList) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(idList)+1)
+	args = append(args, toPlayerId)
+	for _, id := range idList {
+		args = append(args, id)
+	}
+
+	command := "DELETE FROM offline_message WHERE ToPlayerId = ? AND Id IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(idList)), ", ") + ");"
+	if _, err := dal.GetDB().Exec(command, args...); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 删除已过期的离线私聊消息
+// expireTime：早于该时间发送的消息视为已过期
+// 返回值：
+// 错误对象
+func DeleteExpiredOfflineMessage(expireTime time.Time) error {
+	command := "DELETE FROM offline_message WHERE SendTime < ?;"
+	if _, err := dal.GetDB().Exec(command, expireTime); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package chatDAL
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 获取会话中指定消息的聊天记录Id
+// conversationKey：会话的键
+// messageId：消息Id
+// 返回值：
+// 聊天记录Id
+// 是否存在
+// 错误对象
+func GetHistoryId(conversationKey, messageId string) (id int64, exists bool, err error) {
+	command := "SELECT Id FROM chat_history WHERE ConversationKey = ? AND MessageId = ?;"
+
+	if err = dal.GetDB().QueryRow(command, conversationKey, messageId).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			// 重置err，使其为nil；因为这代表的是没有查找到数据，而不是真正的错误
+			err = nil
+			return
+		} else {
+			dal.WriteScanError(command, err)
+			return
+		}
+	}
+
+	exists = true
+
+	return
+}
+
+// 更新私聊会话的已读位置（只会向后移动）
+// playerId：玩家Id
+// peerId：会话对方的玩家Id
+// lastReadId：已读的最后一条聊天记录的Id
+// 返回值：
+// 错误对象
+func UpdateLastReadId(playerId, peerId string, lastReadId int64) error {
+	command := `INSERT INTO 
+                private_read(PlayerId, PeerId, LastReadId)
+            VALUES
+                (?, ?, ?)
+			ON DUPLICATE KEY UPDATE LastReadId = GREATEST(LastReadId, VALUES(LastReadId));
+    `
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(playerId, peerId, lastReadId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 获取玩家每个私聊会话的未读数量（只统计指定时间之后发送的消息，不统计已屏蔽的玩家发送的消息）
+// playerId：玩家Id
+// peerId：会话对方的玩家Id（为空表示所有会话）
+// sinceTime：早于该时间发送的消息不统计
+// 返回值：
+// 未读数量集合（键为会话对方的玩家Id，只包含未读数量大于0的会话）
+// 错误对象
+func GetUnreadCountMap(playerId, peerId string, sinceTime time.Time) (unreadCountMap map[string]int, err error) {
+	command := `SELECT 
+					h.PlayerId, COUNT(*)
+				FROM 
+					chat_history h LEFT JOIN private_read r ON r.PlayerId = h.ToPlayerId AND r.PeerId = h.PlayerId
+				WHERE 
+					h.ToPlayerId = ? AND h.ChannelType = ? AND h.SendTime >= ? AND (? = '' OR h.PlayerId = ?) AND h.IsDeleted = 0 AND h.Id > IFNULL(r.LastReadId, 0)
+					AND NOT EXISTS (SELECT 1 FROM player_block b WHERE b.PlayerId = h.ToPlayerId AND b.BlockedId = h.PlayerId)
+				GROUP BY h.PlayerId;`
+
+	rows, err := dal.GetDB().Query(command, playerId, channelType.Private, sinceTime, peerId, peerId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	unreadCountMap = make(map[string]int, 8)
+	for rows.Next() {
+		var fromPlayerId string
+		var count int
+		if err = rows.Scan(&fromPlayerId, &count); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		unreadCountMap[fromPlayerId] = count
+	}
+
+	return
+}
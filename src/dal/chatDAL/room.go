@@ -0,0 +1,183 @@
+package chatDAL
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+)
+
+// 获取聊天室
+// roomId：聊天室Id
+// 返回值：
+// 聊天室对象
+// 是否存在
+// 错误对象
+func GetRoom(roomId string) (roomObj *model.ChatRoom, exists bool, err error) {
+	command := "SELECT Name, OwnerId, ServerGroupId, IsPublic, CreateTime FROM chat_room WHERE Id = ?;"
+
+	var name string
+	var ownerId string
+	var serverGroupId int
+	var isPublic bool
+	var createTime time.Time
+	if err = dal.GetDB().QueryRow(command, roomId).Scan(&name, &ownerId, &serverGroupId, &isPublic, &createTime); err != nil {
+		if err == sql.ErrNoRows {
+			// 重置err，使其为nil；因为这代表的是没有查找到数据，而不是真正的错误
+			err = nil
+			return
+		} else {
+			dal.WriteScanError(command, err)
+			return
+		}
+	}
+
+	roomObj = model.NewChatRoom(roomId, name, ownerId, serverGroupId, isPublic, createTime)
+	exists = true
+
+	return
+}
+
+// 获取聊天室的成员列表
+// roomId：聊天室Id
+// 返回值：
+// 成员的玩家Id列表（按加入时间从早到晚）
+// 错误对象
+func GetRoomMemberList(roomId string) (memberIdList []string, err error) {
+	command := "SELECT PlayerId FROM chat_room_member WHERE RoomId = ? ORDER BY JoinTime, PlayerId;"
+
+	rows, err := dal.GetDB().Query(command, roomId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	memberIdList = make([]string, 0, 16)
+	for rows.Next() {
+		var playerId string
+		if err = rows.Scan(&playerId); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		memberIdList = append(memberIdList, playerId)
+	}
+
+	return
+}
+
+// 获取玩家加入的聊天室Id列表
+// playerId：玩家Id
+// 返回值：
+// 聊天室Id列表（按加入时间从早到晚）
+// 错误对象
+func GetPlayerRoomIdList(playerId string) (roomIdList []string, err error) {
+	command := "SELECT RoomId FROM chat_room_member WHERE PlayerId = ? ORDER BY JoinTime;"
+
+	rows, err := dal.GetDB().Query(command, playerId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	roomIdList = make([]string, 0, 8)
+	for rows.Next() {
+		var roomId string
+		if err = rows.Scan(&roomId); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		roomIdList = append(roomIdList, roomId)
+	}
+
+	return
+}
+
+// 保存聊天室
+// roomObj：聊天室对象
+// 返回值：
+// 错误对象
+func InsertRoom(roomObj *model.ChatRoom) error {
+	command := `INSERT INTO 
+                chat_room(Id, Name, OwnerId, ServerGroupId, IsPublic, CreateTime)
+            VALUES
+                (?, ?, ?, ?, ?, ?);
+    `
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(roomObj.Id, roomObj.Name, roomObj.OwnerId, roomObj.ServerGroupId, roomObj.IsPublic, roomObj.CreateTime); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 更新聊天室的房主
+// roomObj：聊天室对象（OwnerId已更新）
+// 返回值：
+// 错误对象
+func UpdateRoomOwner(roomObj *model.ChatRoom) error {
+	command := "UPDATE chat_room SET OwnerId = ? WHERE Id = ?;"
+	if _, err := dal.GetDB().Exec(command, roomObj.OwnerId, roomObj.Id); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 删除聊天室（最后一个成员离开时调用）
+// roomId：聊天室Id
+// 返回值：
+// 错误对象
+func DeleteRoom(roomId string) error {
+	command := "DELETE FROM chat_room WHERE Id = ?;"
+	if _, err := dal.GetDB().Exec(command, roomId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 添加聊天室的成员（已是成员时忽略）
+// roomId：聊天室Id
+// playerId：玩家Id
+// 返回值：
+// 错误对象
+func InsertRoomMember(roomId, playerId string) error {
+	command := "INSERT IGNORE INTO chat_room_member(RoomId, PlayerId, JoinTime) VALUES(?, ?, ?);"
+	if _, err := dal.GetDB().Exec(command, roomId, playerId, time.Now()); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 删除聊天室的成员
+// roomId：聊天室Id
+// playerId：玩家Id
+// 返回值：
+// 错误对象
+func DeleteRoomMember(roomId, playerId string) error {
+	command := "DELETE FROM chat_room_member WHERE RoomId = ? AND PlayerId = ?;"
+	if _, err := dal.GetDB().Exec(command, roomId, playerId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
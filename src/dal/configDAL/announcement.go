@@ -0,0 +1,39 @@
+package configDAL
+
+import (
+	"time"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+)
+
+// 初始化未结束的系统公告配置
+// now：当前时间
+func InitAnnouncement(now time.Time) (announcementList []*model.Announcement, err error) {
+	command := "SELECT Id, Message, ServerGroupIds, StartTime, EndTime, RepeatSeconds, Priority FROM config_announcement WHERE EndTime > ?;"
+
+	rows, err := dal.GetDB().Query(command, now)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var message string
+		var serverGroupIds string
+		var startTime time.Time
+		var endTime time.Time
+		var repeatSeconds int
+		var priority int
+		if err = rows.Scan(&id, &message, &serverGroupIds, &startTime, &endTime, &repeatSeconds, &priority); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		announcementList = append(announcementList, model.NewAnnouncement(id, message, serverGroupIds, startTime, endTime, repeatSeconds, priority))
+	}
+
+	return
+}
@@ -0,0 +1,34 @@
+package configDAL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 初始化频道的消息过滤配置
+func InitChannelFilter() (channelFilterList []*model.ChannelFilter, err error) {
+	command := "SELECT ChannelType, IfCheckForbid, IfMaskSensitive, MaxMessageLength FROM config_channel_filter;"
+
+	rows, err := dal.GetDB().Query(command)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var _channelType int
+		var ifCheckForbid bool
+		var ifMaskSensitive bool
+		var maxMessageLength int
+		if err = rows.Scan(&_channelType, &ifCheckForbid, &ifMaskSensitive, &maxMessageLength); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		channelFilterList = append(channelFilterList, model.NewChannelFilter(channelType.ChannelType(_channelType), ifCheckForbid, ifMaskSensitive, maxMessageLength))
+	}
+
+	return
+}
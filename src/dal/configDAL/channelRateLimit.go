@@ -0,0 +1,35 @@
+package configDAL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 初始化频道的发送频率限制配置
+func InitChannelRateLimit() (channelRateLimitList []*model.ChannelRateLimit, err error) {
+	command := "SELECT ChannelType, MessageCount, IntervalSeconds, SilentThreshold, SilentSeconds FROM config_channel_rate_limit;"
+
+	rows, err := dal.GetDB().Query(command)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var _channelType int
+		var messageCount int
+		var intervalSeconds int
+		var silentThreshold int
+		var silentSeconds int
+		if err = rows.Scan(&_channelType, &messageCount, &intervalSeconds, &silentThreshold, &silentSeconds); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		channelRateLimitList = append(channelRateLimitList, model.NewChannelRateLimit(channelType.ChannelType(_channelType), messageCount, intervalSeconds, silentThreshold, silentSeconds))
+	}
+
+	return
+}
@@ -2,6 +2,7 @@ package configDAL
 
 import (
 	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/ChatServerModel/src/config"
 )
 
@@ -45,3 +46,25 @@ func Init() (configObj *config.Config, err error) {
 
 	return
 }
+
+// 初始化刷屏检测的配置（与其它配置位于同一张表中）
+func InitSpamConfig() (spamConfigObj *model.SpamConfig, err error) {
+	command := "SELECT SpamWindowSeconds, SpamPlayerRepeatCount, SpamServerGroupRepeatCount, SpamSimilarity, SpamAction FROM config;"
+
+	var windowSeconds int
+	var playerRepeatCount int
+	var serverGroupRepeatCount int
+	var similarity float64
+	var action int
+
+	err = dal.GetDB().QueryRow(command).Scan(&windowSeconds, &playerRepeatCount, &serverGroupRepeatCount, &similarity, &action)
+	if err != nil {
+		dal.WriteScanError(command, err)
+		return
+	}
+
+	// 构造对象
+	spamConfigObj = model.NewSpamConfig(windowSeconds, playerRepeatCount, serverGroupRepeatCount, similarity, model.SpamAction(action))
+
+	return
+}
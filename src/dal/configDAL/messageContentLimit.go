@@ -0,0 +1,33 @@
+package configDAL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
+)
+
+// 初始化结构化消息的限制配置
+func InitMessageContentLimit() (messageContentLimitList []*model.MessageContentLimit, err error) {
+	command := "SELECT ContentType, MaxSize, MaxDuration, MaxFieldLength FROM config_message_content_limit;"
+
+	rows, err := dal.GetDB().Query(command)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var contentType int
+		var maxSize int
+		var maxDuration int
+		var maxFieldLength int
+		if err = rows.Scan(&contentType, &maxSize, &maxDuration, &maxFieldLength); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		messageContentLimitList = append(messageContentLimitList, model.NewMessageContentLimit(model.MessageContentType(contentType), maxSize, maxDuration, maxFieldLength))
+	}
+
+	return
+}
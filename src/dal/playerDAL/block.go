@@ -0,0 +1,110 @@
+package playerDAL
+
+import (
+	"database/sql"
+
+	"github.com/Jordanzuo/ChatServer/src/dal"
+)
+
+// 获取玩家屏蔽的玩家Id列表
+// playerId：玩家Id
+// 返回值：
+// 被屏蔽的玩家Id列表
+// 错误对象
+func GetBlockList(playerId string) (blockedIdList []string, err error) {
+	command := "SELECT BlockedId FROM player_block WHERE PlayerId = ? ORDER BY CreateTime;"
+
+	rows, err := dal.GetDB().Query(command, playerId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	blockedIdList = make([]string, 0, 8)
+	for rows.Next() {
+		var blockedId string
+		if err = rows.Scan(&blockedId); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		blockedIdList = append(blockedIdList, blockedId)
+	}
+
+	return
+}
+
+// 判断玩家是否屏蔽了另一个玩家
+// playerId：玩家Id
+// blockedId：另一个玩家的Id
+// 返回值：
+// 是否屏蔽
+// 错误对象
+func IfBlocked(playerId, blockedId string) (isBlocked bool, err error) {
+	command := "SELECT 1 FROM player_block WHERE PlayerId = ? AND BlockedId = ?;"
+
+	var value int
+	if err = dal.GetDB().QueryRow(command, playerId, blockedId).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			// 重置err，使其为nil；因为这代表的是没有查找到数据，而不是真正的错误
+			err = nil
+			return
+		} else {
+			dal.WriteScanError(command, err)
+			return
+		}
+	}
+
+	isBlocked = true
+
+	return
+}
+
+// 添加屏蔽（已屏蔽时忽略）
+// playerId：玩家Id
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 错误对象
+func InsertBlock(playerId, blockedId string) error {
+	command := "INSERT IGNORE INTO player_block(PlayerId, BlockedId, CreateTime) VALUES(?, ?, NOW());"
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(playerId, blockedId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
+
+// 取消屏蔽
+// playerId：玩家Id
+// blockedId：被屏蔽的玩家Id
+// 返回值：
+// 错误对象
+func DeleteBlock(playerId, blockedId string) error {
+	command := "DELETE FROM player_block WHERE PlayerId = ? AND BlockedId = ?;"
+	stmt, err := dal.GetDB().Prepare(command)
+	if err != nil {
+		dal.WritePrepareError(command, err)
+		return err
+	}
+
+	// 最后关闭
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(playerId, blockedId); err != nil {
+		dal.WriteExecError(command, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package wordDAL
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/dal"
+)
+
+// 初始化形近字（包括繁简体）映射列表
+// 返回值：
+// 原字符与目标字符的映射
+// 错误对象
+func InitHomoglyph() (homoglyphMap map[string]string, err error) {
+	command := "SELECT Source, Target FROM config_word_homoglyph;"
+
+	rows, err := dal.GetDB().Query(command)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	homoglyphMap = make(map[string]string, 1024)
+	for rows.Next() {
+		var source string
+		var target string
+		if err = rows.Scan(&source, &target); err != nil {
+			dal.WriteScanError(command, err)
+			return
+		}
+
+		homoglyphMap[source] = target
+	}
+
+	return
+}
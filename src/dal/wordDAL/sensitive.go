@@ -2,11 +2,12 @@ package wordDAL
 
 import (
 	"github.com/Jordanzuo/ChatServer/src/dal"
+	"github.com/Jordanzuo/ChatServer/src/model"
 )
 
-// 初始化敏感词列表
-func InitSensitive() (wordList []string, err error) {
-	command := "SELECT Word FROM config_word_sensitive;"
+// 初始化敏感词列表（包括普通词汇、正则表达式和白名单）
+func InitSensitive() (ruleList []*model.WordRule, err error) {
+	command := "SELECT Word, RuleType FROM config_word_sensitive;"
 
 	rows, err := dal.GetDB().Query(command)
 	if err != nil {
@@ -17,12 +18,13 @@ func InitSensitive() (wordList []string, err error) {
 
 	for rows.Next() {
 		var word string
-		if err = rows.Scan(&word); err != nil {
+		var ruleType int
+		if err = rows.Scan(&word, &ruleType); err != nil {
 			dal.WriteScanError(command, err)
 			return
 		}
 
-		wordList = append(wordList, word)
+		ruleList = append(ruleList, model.NewWordRule(word, model.WordRuleType(ruleType)))
 	}
 
 	return
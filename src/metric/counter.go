@@ -0,0 +1,99 @@
+package metric
+
+import (
+	"bytes"
+	"sync"
+)
+
+// 计数器（可带一个标签）
+type Counter struct {
+	// 指标名称
+	name string
+
+	// 指标说明
+	help string
+
+	// 标签名称（为空表示没有标签）
+	labelName string
+
+	// 标签值对应的计数，及其锁对象
+	valueMap map[string]float64
+	mutex    sync.Mutex
+}
+
+// 计数加1
+// labelValue：标签值（没有标签时传空字符串）
+func (c *Counter) Inc(labelValue string) {
+	c.Add(labelValue, 1)
+}
+
+// 计数增加指定的值
+// labelValue：标签值（没有标签时传空字符串）
+// value：增加的值
+func (c *Counter) Add(labelValue string, value float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.valueMap[labelValue] += value
+}
+
+func (c *Counter) write(buffer *bytes.Buffer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	writeHeader(buffer, c.name, c.help, "counter")
+	writeSampleMap(buffer, c.name, c.labelName, c.valueMap)
+}
+
+// 新建计数器，并注册
+// name：指标名称
+// help：指标说明
+// labelName：标签名称（为空表示没有标签）
+// 返回值：
+// 计数器
+func NewCounter(name, help, labelName string) *Counter {
+	counterObj := &Counter{
+		name:      name,
+		help:      help,
+		labelName: labelName,
+		valueMap:  make(map[string]float64, 8),
+	}
+
+	register(counterObj)
+
+	return counterObj
+}
+
+// 即时值（在输出时调用方法获取）
+type gaugeFunc struct {
+	// 指标名称
+	name string
+
+	// 指标说明
+	help string
+
+	// 标签名称（为空表示没有标签）
+	labelName string
+
+	// 获取标签值对应的即时值的方法
+	valueFunc func() map[string]float64
+}
+
+func (g *gaugeFunc) write(buffer *bytes.Buffer) {
+	writeHeader(buffer, g.name, g.help, "gauge")
+	writeSampleMap(buffer, g.name, g.labelName, g.valueFunc())
+}
+
+// 注册即时值
+// name：指标名称
+// help：指标说明
+// labelName：标签名称（为空表示没有标签，valueFunc返回的标签值为空字符串）
+// valueFunc：获取标签值对应的即时值的方法
+func RegisterGaugeFunc(name, help, labelName string, valueFunc func() map[string]float64) {
+	register(&gaugeFunc{
+		name:      name,
+		help:      help,
+		labelName: labelName,
+		valueFunc: valueFunc,
+	})
+}
@@ -0,0 +1,128 @@
+package metric
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// 直方图
+type Histogram struct {
+	// 指标名称
+	name string
+
+	// 指标说明
+	help string
+
+	// 桶的上限（升序）
+	bucketList []float64
+
+	// 每个桶的数量（不累计）、总和、总数量，及其锁对象
+	countList []uint64
+	sum       float64
+	count     uint64
+	mutex     sync.Mutex
+}
+
+// 记录一个观测值
+// value：观测值
+func (h *Histogram) Observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	observe(h.bucketList, h.countList, value)
+	h.sum += value
+	h.count++
+}
+
+func (h *Histogram) write(buffer *bytes.Buffer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	writeHistogram(buffer, h.name, h.help, h.bucketList, h.countList, h.sum, h.count)
+}
+
+// 新建直方图，并注册
+// name：指标名称
+// help：指标说明
+// bucketList：桶的上限（升序）
+// 返回值：
+// 直方图
+func NewHistogram(name, help string, bucketList []float64) *Histogram {
+	histogramObj := &Histogram{
+		name:       name,
+		help:       help,
+		bucketList: bucketList,
+		countList:  make([]uint64, len(bucketList)),
+	}
+
+	register(histogramObj)
+
+	return histogramObj
+}
+
+// 即时直方图（在输出时调用方法获取所有的观测值）
+type histogramFunc struct {
+	// 指标名称
+	name string
+
+	// 指标说明
+	help string
+
+	// 桶的上限（升序）
+	bucketList []float64
+
+	// 获取所有观测值的方法
+	valueFunc func() []float64
+}
+
+func (h *histogramFunc) write(buffer *bytes.Buffer) {
+	countList := make([]uint64, len(h.bucketList))
+	sum := 0.0
+	valueList := h.valueFunc()
+	for _, value := range valueList {
+		observe(h.bucketList, countList, value)
+		sum += value
+	}
+
+	writeHistogram(buffer, h.name, h.help, h.bucketList, countList, sum, uint64(len(valueList)))
+}
+
+// 注册即时直方图
+// name：指标名称
+// help：指标说明
+// bucketList：桶的上限（升序）
+// valueFunc：获取所有观测值的方法
+func RegisterHistogramFunc(name, help string, bucketList []float64, valueFunc func() []float64) {
+	register(&histogramFunc{
+		name:       name,
+		help:       help,
+		bucketList: bucketList,
+		valueFunc:  valueFunc,
+	})
+}
+
+// 将观测值计入对应的桶（超过所有桶上限的只计入总数）
+func observe(bucketList []float64, countList []uint64, value float64) {
+	for index, upperBound := range bucketList {
+		if value <= upperBound {
+			countList[index]++
+			return
+		}
+	}
+}
+
+// 输出直方图（桶的数量为累计值）
+func writeHistogram(buffer *bytes.Buffer, name, help string, bucketList []float64, countList []uint64, sum float64, count uint64) {
+	writeHeader(buffer, name, help, "histogram")
+
+	var cumulativeCount uint64
+	for index, upperBound := range bucketList {
+		cumulativeCount += countList[index]
+		fmt.Fprintf(buffer, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(upperBound), cumulativeCount)
+	}
+
+	fmt.Fprintf(buffer, "%s_bucket{le=\"+Inf\"} %d\n", name, count)
+	fmt.Fprintf(buffer, "%s_sum %s\n", name, formatFloat(sum))
+	fmt.Fprintf(buffer, "%s_count %d\n", name, count)
+}
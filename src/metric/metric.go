@@ -0,0 +1,113 @@
+package metric
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+// 指标对象（以Prometheus文本格式输出）
+type collector interface {
+	// 将指标写入缓冲区
+	write(buffer *bytes.Buffer)
+}
+
+var (
+	// 已注册的指标列表，及其锁对象
+	collectorList  = make([]collector, 0, 16)
+	collectorMutex sync.RWMutex
+)
+
+// 注册指标
+// collectorObj：指标对象
+func register(collectorObj collector) {
+	collectorMutex.Lock()
+	defer collectorMutex.Unlock()
+
+	collectorList = append(collectorList, collectorObj)
+}
+
+// 输出指标的HELP、TYPE信息
+// buffer：缓冲区
+// name：指标名称
+// help：指标说明
+// metricType：指标类型
+func writeHeader(buffer *bytes.Buffer, name, help, metricType string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", name, metricType)
+}
+
+// 输出一个样本
+// buffer：缓冲区
+// name：指标名称
+// labelName：标签名称（为空表示没有标签）
+// labelValue：标签值
+// value：样本值
+func writeSample(buffer *bytes.Buffer, name, labelName, labelValue string, value float64) {
+	if labelName == "" {
+		fmt.Fprintf(buffer, "%s %s\n", name, formatFloat(value))
+	} else {
+		fmt.Fprintf(buffer, "%s{%s=%q} %s\n", name, labelName, labelValue, formatFloat(value))
+	}
+}
+
+// 格式化浮点数
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// 按标签值排序后输出（使每次输出的顺序一致）
+// buffer：缓冲区
+// name：指标名称
+// labelName：标签名称
+// valueMap：标签值对应的样本值
+func writeSampleMap(buffer *bytes.Buffer, name, labelName string, valueMap map[string]float64) {
+	labelValueList := make([]string, 0, len(valueMap))
+	for labelValue := range valueMap {
+		labelValueList = append(labelValueList, labelValue)
+	}
+	sort.Strings(labelValueList)
+
+	for _, labelValue := range labelValueList {
+		writeSample(buffer, name, labelName, labelValue, valueMap[labelValue])
+	}
+}
+
+// 处理指标请求
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	buffer := new(bytes.Buffer)
+
+	collectorMutex.RLock()
+	for _, collectorObj := range collectorList {
+		collectorObj.write(buffer)
+	}
+	collectorMutex.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buffer.Bytes())
+}
+
+// 启动指标服务器（监听失败只记录日志，不影响聊天服务）
+// listenAddress：监听地址
+func StartServer(listenAddress string) {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	logUtil.Log(fmt.Sprintf("指标服务器开始监听:%s", listenAddress), logUtil.Info, true)
+
+	if err := http.ListenAndServe(listenAddress, mux); err != nil {
+		logUtil.Log(fmt.Sprintf("指标服务器监听出错，错误信息为：%s", err), logUtil.Error, true)
+	}
+}
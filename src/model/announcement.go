@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 系统公告
+type Announcement struct {
+	// 公告Id
+	Id int
+
+	// 公告内容
+	Message string
+
+	// 目标服务器组Id列表（以,分隔；为空或为0表示所有服务器组）
+	ServerGroupIds string
+
+	// 开始时间
+	StartTime time.Time
+
+	// 结束时间（结束后不再推送，登陆时也不再发送）
+	EndTime time.Time
+
+	// 重复推送的间隔（单位：秒；<=0表示只在开始时推送一次）
+	RepeatSeconds int
+
+	// 优先级（越大越优先；同时推送时按优先级从高到低的顺序，客户端也可据此决定展示方式）
+	Priority int
+}
+
+// 新建系统公告
+// id：公告Id
+// message：公告内容
+// serverGroupIds：目标服务器组Id列表（以,分隔）
+// startTime：开始时间
+// endTime：结束时间
+// repeatSeconds：重复推送的间隔（单位：秒）
+// priority：优先级
+// 返回值：
+// 系统公告对象
+func NewAnnouncement(id int, message, serverGroupIds string, startTime, endTime time.Time, repeatSeconds, priority int) *Announcement {
+	return &Announcement{
+		Id:             id,
+		Message:        message,
+		ServerGroupIds: serverGroupIds,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		RepeatSeconds:  repeatSeconds,
+		Priority:       priority,
+	}
+}
+
+// 是否在有效期内
+// now：当前时间
+// 返回值：
+// 是否在有效期内
+func (a *Announcement) IfActive(now time.Time) bool {
+	return !now.Before(a.StartTime) && now.Before(a.EndTime)
+}
+
+// 是否重复推送
+// 返回值：
+// 是否重复推送
+func (a *Announcement) IfRepeat() bool {
+	return a.RepeatSeconds > 0
+}
+
+// 获取重复推送的间隔
+// 返回值：
+// 重复推送的间隔
+func (a *Announcement) GetRepeatInterval() time.Duration {
+	return time.Duration(a.RepeatSeconds) * time.Second
+}
+
+// 是否推送给指定服务器组的玩家
+// serverGroupId：服务器组Id
+// 返回值：
+// 是否推送
+func (a *Announcement) IfTargetServerGroup(serverGroupId int) bool {
+	serverGroupIdList, err := a.GetServerGroupIdList()
+	if err != nil {
+		return false
+	}
+
+	if len(serverGroupIdList) == 0 {
+		return true
+	}
+
+	for _, item := range serverGroupIdList {
+		if item == serverGroupId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 获取目标服务器组Id列表
+// 返回值：
+// 目标服务器组Id列表（为空表示所有服务器组）
+// 错误对象
+func (a *Announcement) GetServerGroupIdList() ([]int, error) {
+	if a.ServerGroupIds == "" || a.ServerGroupIds == "0" {
+		return nil, nil
+	}
+
+	serverGroupIdList := make([]int, 0, 8)
+	for _, item := range strings.Split(a.ServerGroupIds, ",") {
+		serverGroupId, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("ServerGroupIds:%s不正确", a.ServerGroupIds)
+		}
+
+		serverGroupIdList = append(serverGroupIdList, serverGroupId)
+	}
+
+	return serverGroupIdList, nil
+}
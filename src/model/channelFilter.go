@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 频道的消息过滤配置
+type ChannelFilter struct {
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 是否检查屏蔽词（包含则拒绝发送）
+	IfCheckForbid bool
+
+	// 是否处理敏感词（替换为*）
+	IfMaskSensitive bool
+
+	// 消息的最大长度（<=0表示使用config表中的MaxMessageLength）
+	MaxMessageLength int
+}
+
+// 新建频道的消息过滤配置
+// _channelType：频道类型
+// ifCheckForbid：是否检查屏蔽词
+// ifMaskSensitive：是否处理敏感词
+// maxMessageLength：消息的最大长度
+// 返回值：
+// 频道的消息过滤配置
+func NewChannelFilter(_channelType channelType.ChannelType, ifCheckForbid, ifMaskSensitive bool, maxMessageLength int) *ChannelFilter {
+	return &ChannelFilter{
+		ChannelType:      _channelType,
+		IfCheckForbid:    ifCheckForbid,
+		IfMaskSensitive:  ifMaskSensitive,
+		MaxMessageLength: maxMessageLength,
+	}
+}
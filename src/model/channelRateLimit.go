@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 频道的发送频率限制配置（令牌桶：桶的容量为MessageCount，每IntervalSeconds秒补充MessageCount个令牌）
+type ChannelRateLimit struct {
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 每个时间间隔内允许发送的消息数量
+	MessageCount int
+
+	// 时间间隔（单位：秒）
+	IntervalSeconds int
+
+	// 自动禁言的阈值：在统计时间内被限制的次数达到该值时自动禁言（<=0表示不自动禁言）
+	SilentThreshold int
+
+	// 自动禁言的时长（单位：秒）
+	SilentSeconds int
+}
+
+// 新建频道的发送频率限制配置
+// _channelType：频道类型
+// messageCount：每个时间间隔内允许发送的消息数量
+// intervalSeconds：时间间隔（单位：秒）
+// silentThreshold：自动禁言的阈值
+// silentSeconds：自动禁言的时长（单位：秒）
+// 返回值：
+// 频道的发送频率限制配置
+func NewChannelRateLimit(_channelType channelType.ChannelType, messageCount, intervalSeconds, silentThreshold, silentSeconds int) *ChannelRateLimit {
+	return &ChannelRateLimit{
+		ChannelType:     _channelType,
+		MessageCount:    messageCount,
+		IntervalSeconds: intervalSeconds,
+		SilentThreshold: silentThreshold,
+		SilentSeconds:   silentSeconds,
+	}
+}
+
+// 获取令牌的补充速度
+// 返回值：
+// 每秒补充的令牌数量
+func (c *ChannelRateLimit) GetRefillRate() float64 {
+	return float64(c.MessageCount) / float64(c.IntervalSeconds)
+}
+
+// 是否自动禁言
+// 返回值：
+// 是否自动禁言
+func (c *ChannelRateLimit) IfAutoSilent() bool {
+	return c.SilentThreshold > 0 && c.SilentSeconds > 0
+}
+
+// 获取自动禁言的时长
+// 返回值：
+// 自动禁言的时长
+func (c *ChannelRateLimit) GetSilentDuration() time.Duration {
+	return time.Duration(c.SilentSeconds) * time.Second
+}
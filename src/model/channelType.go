@@ -0,0 +1,14 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+)
+
+// 本服务器扩展的频道类型（ChatServerModel中尚未定义；取值从101开始，以免与ChatServerModel中的冲突）
+const (
+	// 聊天室（玩家自行创建的群聊，如队伍、团队、好友群；成员需在同一个服务器组）
+	Con_Channel_Room channelType.ChannelType = 101 + iota
+
+	// 系统公告（由服务器按照配置定时推送，玩家不能发送）
+	Con_Channel_Announcement
+)
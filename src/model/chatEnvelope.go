@@ -0,0 +1,162 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+const (
+	// 信封的前缀（用于区分信封与普通的消息内容，如后台推送的消息）
+	con_ChatEnvelopePrefix = "\x00ChatEnvelope:"
+)
+
+// 信封的类型
+type ChatEnvelopeType int
+
+const (
+	// 聊天消息
+	Con_Envelope_Message ChatEnvelopeType = iota
+
+	// 私聊消息的回执
+	Con_Envelope_Receipt
+
+	// 聊天室的变化（成员加入、离开）
+	Con_Envelope_RoomChange
+
+	// 发送者撤回消息
+	Con_Envelope_Recall
+)
+
+// 私聊消息的回执状态
+type ReceiptStatus int
+
+const (
+	// 服务器已接收（分配了消息Id）
+	Con_Receipt_Sent ReceiptStatus = 1 + iota
+
+	// 已写入目标玩家的客户端连接
+	Con_Receipt_Delivered
+
+	// 发送失败
+	Con_Receipt_Failed
+
+	// 目标玩家已读
+	Con_Receipt_Read
+
+	// 目标玩家不在线，已保存为离线私聊消息（在其登陆后送达，届时再推送送达回执）
+	Con_Receipt_Stored
+)
+
+// 聊天消息的信封：ChatMessageObject的结构由ChatServerModel定义，无法增加字段，
+// 故将本服务器扩展的信息与消息内容一起序列化后放在其Message字段中，经Center转发给其它ChatServer
+type ChatEnvelope struct {
+	// 类型
+	Type ChatEnvelopeType
+
+	// 消息Id
+	MessageId string
+
+	// 消息内容（结构化消息时为替代文本）
+	Message string
+
+	// 结构化消息的内容（纯文本时为空）
+	Content *MessageContent `json:",omitempty"`
+
+	// 回执状态（回执时有效）
+	ReceiptStatus ReceiptStatus `json:",omitempty"`
+
+	// 失败的原因（回执状态为失败时有效）
+	ResultStatus serverResponseObject.ResultStatus `json:",omitempty"`
+
+	// 加入或离开的玩家Id（聊天室变化时有效）
+	PlayerId string `json:",omitempty"`
+
+	// 消息中提及（@）的玩家Id列表（已在发送方所在的服务器验证过）
+	MentionIdList []string `json:",omitempty"`
+}
+
+// 新建聊天消息的信封
+// messageId：消息Id
+// message：消息内容
+// contentObj：结构化消息的内容（纯文本时为nil）
+// 返回值：
+// 信封对象
+func NewMessageEnvelope(messageId, message string, contentObj *MessageContent) *ChatEnvelope {
+	return &ChatEnvelope{
+		Type:      Con_Envelope_Message,
+		MessageId: messageId,
+		Message:   message,
+		Content:   contentObj,
+	}
+}
+
+// 新建私聊消息回执的信封
+// messageId：消息Id
+// receiptStatus：回执状态
+// resultStatus：失败的原因
+// 返回值：
+// 信封对象
+func NewReceiptEnvelope(messageId string, receiptStatus ReceiptStatus, resultStatus serverResponseObject.ResultStatus) *ChatEnvelope {
+	return &ChatEnvelope{
+		Type:          Con_Envelope_Receipt,
+		MessageId:     messageId,
+		ReceiptStatus: receiptStatus,
+		ResultStatus:  resultStatus,
+	}
+}
+
+// 新建聊天室变化的信封
+// playerId：加入或离开的玩家Id
+// 返回值：
+// 信封对象
+func NewRoomChangeEnvelope(playerId string) *ChatEnvelope {
+	return &ChatEnvelope{
+		Type:     Con_Envelope_RoomChange,
+		PlayerId: playerId,
+	}
+}
+
+// 新建撤回消息的信封
+// messageId：被撤回的消息Id
+// 返回值：
+// 信封对象
+func NewRecallEnvelope(messageId string) *ChatEnvelope {
+	return &ChatEnvelope{
+		Type:      Con_Envelope_Recall,
+		MessageId: messageId,
+	}
+}
+
+// 序列化为ChatMessageObject的Message字段
+// 返回值：
+// 序列化后的内容
+func (e *ChatEnvelope) Encode() string {
+	// 只包含基础类型，不会出错
+	content, _ := json.Marshal(e)
+	return con_ChatEnvelopePrefix + string(content)
+}
+
+// 判断内容是否为信封（客户端发送的消息内容不能以信封的前缀开头，以免被当作信封处理）
+// message：消息内容
+// 返回值：
+// 是否为信封
+func IfChatEnvelope(message string) bool {
+	return strings.HasPrefix(message, con_ChatEnvelopePrefix)
+}
+
+// 从ChatMessageObject的Message字段解析信封（不是信封的内容视为没有消息Id的聊天消息）
+// message：Message字段
+// 返回值：
+// 信封对象
+func DecodeChatEnvelope(message string) *ChatEnvelope {
+	if IfChatEnvelope(message) {
+		envelopeObj := new(ChatEnvelope)
+		if err := json.Unmarshal([]byte(message[len(con_ChatEnvelopePrefix):]), envelopeObj); err == nil {
+			return envelopeObj
+		}
+	}
+
+	return NewMessageEnvelope("", message, nil)
+}
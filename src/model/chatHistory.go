@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+)
+
+// 聊天记录
+type ChatHistory struct {
+	// 自增Id（尚未保存到数据库的为0）
+	Id int64
+
+	// 消息Id
+	MessageId string
+
+	// 会话的键（由频道类型、服务器组、公会、私聊双方等组成，用于查询同一会话的记录）
+	ConversationKey string
+
+	// 频道类型
+	ChannelType channelType.ChannelType
+
+	// 发送者的服务器组Id
+	ServerGroupId int
+
+	// 发送者的信息（用于重建发送者对象）
+	PlayerId  string
+	Name      string
+	PartnerId int
+	ServerId  int
+	UnionId   string
+	ExtraMsg  string
+
+	// 目标玩家Id（私聊时有效）
+	ToPlayerId string
+
+	// 消息内容（已过滤；结构化消息时为替代文本）
+	Message string
+
+	// 结构化消息的内容（纯文本时为nil）
+	Content *MessageContent
+
+	// 发送时间
+	SendTime time.Time
+
+	// 是否已被撤回或删除
+	IsDeleted bool
+}
+
+// 新建聊天记录
+// messageId：消息Id
+// conversationKey：会话的键
+// _channelType：频道类型
+// playerObj：发送者
+// toPlayerId：目标玩家Id
+// message：消息内容
+// sendTime：发送时间
+// 返回值：
+// 聊天记录
+func NewChatHistory(messageId, conversationKey string, _channelType channelType.ChannelType, playerObj *player.Player, toPlayerId, message string, sendTime time.Time) *ChatHistory {
+	return &ChatHistory{
+		MessageId:       messageId,
+		ConversationKey: conversationKey,
+		ChannelType:     _channelType,
+		ServerGroupId:   playerObj.ServerGroupId,
+		PlayerId:        playerObj.Id,
+		Name:            playerObj.Name,
+		PartnerId:       playerObj.PartnerId,
+		ServerId:        playerObj.ServerId,
+		UnionId:         playerObj.UnionId,
+		ExtraMsg:        playerObj.ExtraMsg,
+		ToPlayerId:      toPlayerId,
+		Message:         message,
+		SendTime:        sendTime,
+	}
+}
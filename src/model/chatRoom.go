@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// 聊天室
+type ChatRoom struct {
+	// 聊天室Id
+	Id string
+
+	// 名称
+	Name string
+
+	// 房主的玩家Id（房主离开后由最早加入的成员接替）
+	OwnerId string
+
+	// 所属的服务器组Id（只有同一服务器组的玩家可以加入）
+	ServerGroupId int
+
+	// 是否公开（公开的聊天室可以直接加入，否则只能由成员邀请）
+	IsPublic bool
+
+	// 创建时间
+	CreateTime time.Time
+}
+
+// 新建聊天室
+// id：聊天室Id
+// name：名称
+// ownerId：房主的玩家Id
+// serverGroupId：所属的服务器组Id
+// isPublic：是否公开
+// createTime：创建时间
+// 返回值：
+// 聊天室对象
+func NewChatRoom(id, name, ownerId string, serverGroupId int, isPublic bool, createTime time.Time) *ChatRoom {
+	return &ChatRoom{
+		Id:            id,
+		Name:          name,
+		OwnerId:       ownerId,
+		ServerGroupId: serverGroupId,
+		IsPublic:      isPublic,
+		CreateTime:    createTime,
+	}
+}
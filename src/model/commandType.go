@@ -0,0 +1,56 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+)
+
+// 本服务器扩展的命令类型（ChatServerModel中尚未定义；取值从101开始，以免与ChatServerModel中的冲突）
+const (
+	// 获取聊天记录
+	Con_Command_FetchHistory commandType.CommandType = 101 + iota
+
+	// 推送离线私聊消息（登陆后由服务器推送）
+	Con_Command_OfflineMessage
+
+	// 推送私聊消息的回执（由服务器推送）
+	Con_Command_MessageReceipt
+
+	// 将私聊会话标记为已读
+	Con_Command_MarkRead
+
+	// 屏蔽玩家
+	Con_Command_AddBlock
+
+	// 取消屏蔽玩家
+	Con_Command_RemoveBlock
+
+	// 获取屏蔽的玩家列表
+	Con_Command_GetBlockList
+
+	// 创建聊天室
+	Con_Command_CreateRoom
+
+	// 加入公开的聊天室
+	Con_Command_JoinRoom
+
+	// 离开聊天室
+	Con_Command_LeaveRoom
+
+	// 邀请玩家加入聊天室
+	Con_Command_InviteRoom
+
+	// 获取已加入的聊天室列表
+	Con_Command_GetRoomList
+
+	// 推送聊天室的变化（成员加入、离开时由服务器推送）
+	Con_Command_RoomChange
+
+	// 撤回自己发送的消息
+	Con_Command_RecallMessage
+
+	// 推送消息被撤回、删除的通知（由服务器推送）
+	Con_Command_MessageRecalled
+
+	// 推送提及（@）自己的消息（由服务器推送；登陆时推送离线期间未读的提及）
+	Con_Command_Mention
+)
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 结构化消息的类型
+type MessageContentType int
+
+const (
+	// 纯文本（消息内容即为Message字段）
+	Con_Content_Text MessageContentType = iota
+
+	// 表情
+	Con_Content_Emoji
+
+	// 链接卡片（道具、英雄等）
+	Con_Content_Link
+
+	// 语音
+	Con_Content_Voice
+
+	// 图片
+	Con_Content_Image
+)
+
+// 结构化消息的内容（与Message字段一起发送；不支持结构化消息的客户端只显示Message字段中的替代文本）
+type MessageContent struct {
+	// 类型
+	Type MessageContentType
+
+	// 表情Id（表情时有效）
+	EmojiId string `json:",omitempty"`
+
+	// 链接的对象类型（如item、hero）、Id和显示名称（链接卡片时有效）
+	LinkType string `json:",omitempty"`
+	LinkId   string `json:",omitempty"`
+	LinkName string `json:",omitempty"`
+
+	// 资源地址（语音、图片时有效）
+	Url string `json:",omitempty"`
+
+	// 时长（单位：秒；语音时有效）
+	Duration int `json:",omitempty"`
+
+	// 文件大小（单位：字节；语音、图片时有效）
+	Size int `json:",omitempty"`
+
+	// 宽度、高度（单位：像素；图片时有效）
+	Width  int `json:",omitempty"`
+	Height int `json:",omitempty"`
+}
+
+// 是否为纯文本（没有结构化内容）
+// 返回值：
+// 是否为纯文本
+func (c *MessageContent) IfText() bool {
+	return c == nil || c.Type == Con_Content_Text
+}
+
+// 获取替代文本（供不支持结构化消息的客户端显示，也用于保存聊天记录、检查屏蔽词等）
+// 返回值：
+// 替代文本
+func (c *MessageContent) GetFallbackMessage() string {
+	switch c.Type {
+	case Con_Content_Emoji:
+		return "[表情]"
+	case Con_Content_Link:
+		return fmt.Sprintf("[%s]", c.LinkName)
+	case Con_Content_Voice:
+		return "[语音]"
+	case Con_Content_Image:
+		return "[图片]"
+	default:
+		return ""
+	}
+}
+
+// 序列化为字符串（用于保存到数据库）
+// 返回值：
+// 序列化后的内容（纯文本时为空）
+func (c *MessageContent) Encode() string {
+	if c.IfText() {
+		return ""
+	}
+
+	// 只包含基础类型，不会出错
+	content, _ := json.Marshal(c)
+	return string(content)
+}
+
+// 从字符串解析结构化消息的内容
+// content：序列化后的内容
+// 返回值：
+// 结构化消息的内容（为空或不正确时为nil）
+func DecodeMessageContent(content string) *MessageContent {
+	if content == "" {
+		return nil
+	}
+
+	contentObj := new(MessageContent)
+	if err := json.Unmarshal([]byte(content), contentObj); err != nil {
+		return nil
+	}
+
+	return contentObj
+}
@@ -0,0 +1,32 @@
+package model
+
+// 结构化消息的限制配置（没有配置的类型不允许发送）
+type MessageContentLimit struct {
+	// 类型
+	ContentType MessageContentType
+
+	// 文件的最大大小（单位：字节；语音、图片时有效）
+	MaxSize int
+
+	// 最大时长（单位：秒；语音时有效）
+	MaxDuration int
+
+	// 标识、名称、地址等文本字段的最大长度（单位：字节）
+	MaxFieldLength int
+}
+
+// 新建结构化消息的限制配置
+// contentType：类型
+// maxSize：文件的最大大小
+// maxDuration：最大时长
+// maxFieldLength：文本字段的最大长度
+// 返回值：
+// 结构化消息的限制配置
+func NewMessageContentLimit(contentType MessageContentType, maxSize, maxDuration, maxFieldLength int) *MessageContentLimit {
+	return &MessageContentLimit{
+		ContentType:    contentType,
+		MaxSize:        maxSize,
+		MaxDuration:    maxDuration,
+		MaxFieldLength: maxFieldLength,
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
+)
+
+// 本服务器扩展的Center消息类型（ChatServerModel中尚未定义）
+const (
+	// 管理员删除消息（ChatMessageObject.Message为被删除的消息Id）
+	Con_MessageType_DeleteMessage transferObject.MessageType = "DeleteMessage"
+)
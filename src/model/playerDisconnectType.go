@@ -0,0 +1,11 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/playerDisconnectType"
+)
+
+// 本服务器扩展的玩家断开连接类型（ChatServerModel中尚未定义；取值从101开始，以免与ChatServerModel中的冲突）
+const (
+	// 客户端接收过慢（待发送数据持续超过高水位）
+	Con_FromSlowConsumer playerDisconnectType.PlayerDisconnectType = 101 + iota
+)
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 本服务器扩展的响应状态（ChatServerModel中尚未定义；取值从1001开始，以免与ChatServerModel中的冲突）
+const (
+	// 服务器维护中（服务器即将关闭）
+	Con_ServerMaintenance serverResponseObject.ResultStatus = 1001 + iota
+
+	// 发送消息过于频繁
+	Con_SendMessageTooFrequently
+
+	// 重复发送相同或相似的消息（刷屏）
+	Con_DuplicateMessage
+
+	// 目标玩家不在线（如果启用了离线私聊消息，则会在其登陆后送达）
+	Con_TargetOffline
+
+	// 目标玩家不在同一个服务器组
+	Con_TargetNotInSameServerGroup
+
+	// 已被目标玩家屏蔽
+	Con_BlockedByTarget
+
+	// 屏蔽的玩家数量已达上限
+	Con_BlockCountLimit
+
+	// 聊天室不存在
+	Con_RoomNotExist
+
+	// 不是聊天室的成员
+	Con_NotInRoom
+
+	// 聊天室的成员数量已达上限
+	Con_RoomIsFull
+
+	// 加入的聊天室数量已达上限
+	Con_RoomCountLimit
+
+	// 聊天室不公开（只能由成员邀请加入）
+	Con_RoomIsNotPublic
+
+	// 消息不存在（或不是自己发送的）
+	Con_MessageNotExist
+
+	// 已超过可以撤回的时间
+	Con_RecallTimeout
+
+	// 结构化消息的内容不正确（类型不允许发送、缺少字段或超过限制）
+	Con_MessageContentError
+
+	// 功能未启用（依赖于ChatServer之间转发的信封，而服务器未启用）
+	Con_FeatureNotEnabled
+)
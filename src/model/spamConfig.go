@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// 刷屏消息的处理方式
+type SpamAction int
+
+const (
+	// 拒绝发送（向客户端返回错误）
+	Con_SpamAction_Reject SpamAction = 1 + iota
+
+	// 静默丢弃（只回显给发送者本人，其他玩家收不到）
+	Con_SpamAction_ShadowDrop
+)
+
+// 刷屏（重复、相似消息）检测的配置
+type SpamConfig struct {
+	// 统计的时间窗口（单位：秒；<=0表示不检测）
+	WindowSeconds int
+
+	// 同一玩家在时间窗口内允许发送的相似消息数量（<=0表示不检测）
+	PlayerRepeatCount int
+
+	// 同一服务器组在时间窗口内允许出现的相似消息数量（用于识别多个账号发送的广告；<=0表示不检测）
+	ServerGroupRepeatCount int
+
+	// 判定为相似的阈值（0~1，1表示只检测完全相同的消息）
+	Similarity float64
+
+	// 处理方式
+	Action SpamAction
+}
+
+// 新建刷屏检测的配置
+// windowSeconds：统计的时间窗口（单位：秒）
+// playerRepeatCount：同一玩家在时间窗口内允许发送的相似消息数量
+// serverGroupRepeatCount：同一服务器组在时间窗口内允许出现的相似消息数量
+// similarity：判定为相似的阈值
+// action：处理方式
+// 返回值：
+// 刷屏检测的配置
+func NewSpamConfig(windowSeconds, playerRepeatCount, serverGroupRepeatCount int, similarity float64, action SpamAction) *SpamConfig {
+	return &SpamConfig{
+		WindowSeconds:          windowSeconds,
+		PlayerRepeatCount:      playerRepeatCount,
+		ServerGroupRepeatCount: serverGroupRepeatCount,
+		Similarity:             similarity,
+		Action:                 action,
+	}
+}
+
+// 是否启用检测
+// 返回值：
+// 是否启用检测
+func (c *SpamConfig) IfEnabled() bool {
+	return c.WindowSeconds > 0
+}
+
+// 获取统计的时间窗口
+// 返回值：
+// 统计的时间窗口
+func (c *SpamConfig) GetWindow() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
@@ -0,0 +1,36 @@
+package model
+
+// 词汇规则类型
+type WordRuleType int
+
+const (
+	// 普通词汇（规范化后使用DFA匹配）
+	Con_WordRule_Literal WordRuleType = 1 + iota
+
+	// 正则表达式（用于匹配QQ号、网址、手机号等模式）
+	Con_WordRule_Regexp
+
+	// 白名单（包含被屏蔽内容的合法词汇，不视为匹配）
+	Con_WordRule_Whitelist
+)
+
+// 词汇规则
+type WordRule struct {
+	// 词汇或正则表达式
+	Word string
+
+	// 规则类型
+	RuleType WordRuleType
+}
+
+// 新建词汇规则
+// word：词汇或正则表达式
+// ruleType：规则类型
+// 返回值：
+// 词汇规则
+func NewWordRule(word string, ruleType WordRuleType) *WordRule {
+	return &WordRule{
+		Word:     word,
+		RuleType: ruleType,
+	}
+}
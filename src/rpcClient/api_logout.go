@@ -0,0 +1,38 @@
+package rpcClient
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Jordanzuo/goutil/debugUtil"
+)
+
+// 通知ChatServerCenter本服务器即将下线
+// ChatServerCenter没有登出的接口，而是在与ChatServer的连接断开时将其移除（不再分配客户端、转发消息），
+// 故此处不再重连，并主动断开连接，等待读取数据的Goroutine退出
+// timeout：等待连接断开的最长时间
+// 返回值：
+// 是否已断开连接
+func Logout(timeout time.Duration) bool {
+	// 登出之后不再重连ChatServerCenter
+	atomic.StoreInt32(&isLogout, 1)
+
+	_clientObj := getClientObj()
+	if _clientObj == nil {
+		return false
+	}
+
+	//断开连接
+	debugUtil.Println("\nSend Logout")
+	_clientObj.conn.Close()
+
+	//阻塞直到连接断开或超时
+	select {
+	case <-_clientObj.closedCh:
+		debugUtil.Println("Logout success")
+		return true
+	case <-time.After(timeout):
+		debugUtil.Println("Logout Timeout")
+		return false
+	}
+}
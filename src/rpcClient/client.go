@@ -29,6 +29,9 @@ type client struct {
 
 	// 接收到的消息内容
 	content []byte
+
+	// 连接断开的通知（读取数据的Goroutine退出时关闭）
+	closedCh chan struct{}
 }
 
 // 追加内容
@@ -113,7 +116,8 @@ func (clientObj *client) sendByteMessage(id int32, message []byte) {
 // 返回值：客户端对象的指针
 func newClient(_conn net.Conn) *client {
 	return &client{
-		conn:    _conn,
-		content: make([]byte, 0, 1024),
+		conn:     _conn,
+		content:  make([]byte, 0, 1024),
+		closedCh: make(chan struct{}),
 	}
 }
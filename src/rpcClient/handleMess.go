@@ -1,6 +1,8 @@
 package rpcClient
 
 import (
+	"time"
+
 	"github.com/Jordanzuo/ChatServerModel/src/centerResponseObject"
 	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
 	"github.com/Jordanzuo/goutil/debugUtil"
@@ -24,6 +26,11 @@ func handlePassiveMess(id int32, responseObj *centerResponseObject.ResponseObjec
 		deleteCallbackFunc(id)
 	}()
 
+	// 记录请求的耗时
+	if requestTime, exists := getRequestTime(id); exists {
+		centerRequestLatency.Observe(time.Since(requestTime).Seconds())
+	}
+
 	// 返回成功，则调用指定的回调方法；否则表示一些提示、警告、或者版本、资源更新等信息；否则表示其它信息的返回
 	if responseObj.Code == centerResponseObject.Con_Success {
 		if callbackFunc == nil {
@@ -0,0 +1,13 @@
+package rpcClient
+
+import (
+	"github.com/Jordanzuo/ChatServer/src/metric"
+)
+
+var (
+	// 请求ChatServerCenter的耗时（单位：秒）
+	centerRequestLatency = metric.NewHistogram("chat_center_rpc_latency_seconds", "请求ChatServerCenter的耗时", []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5})
+
+	// 重连ChatServerCenter的次数（按结果）
+	centerReconnectCounter = metric.NewCounter("chat_center_reconnect_total", "重连ChatServerCenter的次数", "result")
+)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/Jordanzuo/ChatServerModel/src/centerRequestObject"
 	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
@@ -16,8 +17,9 @@ var (
 	// 请求Id:每个请求都会带上一个唯一Id，以便在接收到服务器的返回数据时能够区分出来自于不同的请求
 	requestId int32 = 0
 
-	// 回调方法集合，及其锁对象
+	// 回调方法集合、请求发送时间集合，及其锁对象
 	callbackFuncMap = make(map[int32]func(interface{}))
+	requestTimeMap  = make(map[int32]time.Time)
 	mutex           sync.Mutex
 )
 
@@ -29,6 +31,7 @@ func registerCallbackFunc(id int32, callbackFunc func(interface{})) {
 	defer mutex.Unlock()
 
 	callbackFuncMap[id] = callbackFunc
+	requestTimeMap[id] = time.Now()
 }
 
 // 获取回调方法
@@ -51,6 +54,21 @@ func deleteCallbackFunc(id int32) {
 	defer mutex.Unlock()
 
 	delete(callbackFuncMap, id)
+	delete(requestTimeMap, id)
+}
+
+// 获取请求发送时间
+// id:自增Id
+// 返回值：
+// 请求发送时间
+// 是否存在
+func getRequestTime(id int32) (requestTime time.Time, exists bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	requestTime, exists = requestTimeMap[id]
+
+	return
 }
 
 // 向服务端发送请求
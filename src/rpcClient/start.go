@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
 
 	"github.com/Jordanzuo/ChatServerModel/src/centerResponseObject"
 	"github.com/Jordanzuo/ChatServerModel/src/transferObject"
@@ -20,6 +21,9 @@ var (
 
 	// 存储登陆成功信息的通道
 	loginSucceedCh = make(chan int)
+
+	// 是否已经登出（登出之后不再重连；由关闭服务器的Goroutine写入、重连的Goroutine读取，故使用原子操作）
+	isLogout int32
 )
 
 func init() {
@@ -36,14 +40,16 @@ func init() {
 			// 先休眠5s
 			time.Sleep(5 * time.Second)
 
-			if clientObj == nil && chatServerCenterRpcAddress != "" && chatServerPublicAddress != "" {
+			if clientObj == nil && atomic.LoadInt32(&isLogout) == 0 && chatServerCenterRpcAddress != "" && chatServerPublicAddress != "" {
 				logUtil.Log("与ChatServerCenter的连接已经断开，尝试重连", logUtil.Debug, true)
 				StartClient(false)
 
 				if clientObj != nil {
 					logUtil.Log("与ChatServerCenter重连成功", logUtil.Debug, true)
+					centerReconnectCounter.Inc("success")
 				} else {
 					logUtil.Log("与ChatServerCenter重连失败", logUtil.Debug, true)
+					centerReconnectCounter.Inc("fail")
 				}
 			}
 		}
@@ -142,10 +148,11 @@ func start(ch chan int) {
 	// 发送连接成功的通知
 	ch <- 1
 
-	defer func() {
+	defer func(_clientObj *client) {
 		conn.Close()
 		clientObj = nil
-	}()
+		close(_clientObj.closedCh)
+	}(clientObj)
 
 	// 死循环，不断地读取数据，解析数据，发送数据
 	for {
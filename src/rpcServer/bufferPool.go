@@ -0,0 +1,38 @@
+package rpcServer
+
+import (
+	"bytes"
+	"sync"
+)
+
+const (
+	// 放回缓冲池的缓冲区的最大容量（超过则丢弃，以免长期占用过多内存）
+	con_MaxPooledBufferSize = 64 * 1024
+)
+
+var (
+	// 发送数据时使用的缓冲池
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+// 从缓冲池中获取缓冲区
+// 返回值：
+// 缓冲区（已清空）
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// 将缓冲区放回缓冲池
+// buffer：缓冲区
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > con_MaxPooledBufferSize {
+		return
+	}
+
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
@@ -2,8 +2,6 @@ package rpcServer
 
 import (
 	"encoding/binary"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -11,16 +9,21 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
 	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
 	"github.com/Jordanzuo/goutil/fileUtil"
 	"github.com/Jordanzuo/goutil/intAndBytesUtil"
 	"github.com/Jordanzuo/goutil/logUtil"
 	"github.com/Jordanzuo/goutil/timeUtil"
+	"github.com/gorilla/websocket"
 )
 
 const (
 	// 包头的长度
 	con_HEADER_LENGTH = 4
+
+	// 单条消息的最大长度（TCP为包头中的长度，WebSocket为一帧的长度；超过则断开连接）
+	con_MaxMessageLength = 64 * 1024
 )
 
 var (
@@ -31,6 +34,15 @@ var (
 	byterOrder = binary.LittleEndian
 )
 
+// 待发送的数据项
+type sendDataItem struct {
+	// 预先序列化的响应对象（可被多个客户端共享）
+	responseObj *PreparedResponse
+
+	// 加入待发送队列时客户端使用的编解码器（切换编解码器之前加入的数据仍使用原来的编解码器）
+	codecObj Codec
+}
+
 // 定义客户端对象，以实现对客户端连接的封装
 type Client struct {
 	// 唯一标识
@@ -39,6 +51,21 @@ type Client struct {
 	// 客户端连接对象
 	conn net.Conn
 
+	// 连接类型
+	connType ConnType
+
+	// WebSocket连接对象（仅当connType为con_WebSocket时有效）
+	webSocketConn *websocket.Conn
+
+	// WebSocket回复消息时使用的帧类型（与客户端最近一次发送的帧类型保持一致；由接收、发送Goroutine并发访问，故使用原子操作）
+	webSocketMessageType int32
+
+	// 请求、响应内容的编解码器（默认为JSON，可在登陆时协商；由接收、发送Goroutine并发访问，故通过mutex控制）
+	codec Codec
+
+	// 登陆请求中指定的编解码器（登陆成功的响应加入待发送队列时才切换，登陆失败则丢弃）
+	pendingCodec Codec
+
 	//连接状态 (1:连接中, 2:最后一条消息，3,断开)
 	connStatus ConnStatus
 
@@ -46,12 +73,33 @@ type Client struct {
 	receiveData []byte
 
 	// 待发送的数据
-	sendData []*serverResponseObject.ResponseObject
+	sendData []*sendDataItem
 
 	// 低优先级的待发送的数据
-	sendData_LowPriority []*serverResponseObject.ResponseObject
+	sendData_LowPriority []*sendDataItem
+
+	// 是否有正在发送的数据（已从待发送队列中取出，但尚未发送完成）
+	isSending bool
+
+	// 发送信号（有新的待发送数据或连接状态变化时写入，发送Goroutine据此唤醒；容量为1，多次写入会合并）
+	sendSignal chan struct{}
 
-	// 锁对象（用于控制对sendDatap、sendData_LowPriority的并发访问；receiveData不需要，因为是同步访问）
+	// 待发送数据开始超过高水位的时间（未超过时为零值）
+	overHighWaterMarkTime time.Time
+
+	// 因超过最大长度而丢弃的数据数量
+	droppedCount int
+
+	// 是否已因接收过慢而被驱逐
+	isEvicted bool
+
+	// 已发送的字节数（包括包头）
+	sentBytes int64
+
+	// 已发送的消息数
+	sentFrames int64
+
+	// 锁对象（用于控制对sendDatap、sendData_LowPriority、isSending、codec、pendingCodec的并发访问；receiveData不需要，因为是同步访问）
 	mutex sync.Mutex
 
 	// 玩家Id
@@ -73,6 +121,36 @@ func (c *Client) GetPlayerId() string {
 	return c.playerId
 }
 
+// 获取编解码器
+func (c *Client) getCodec() Codec {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.codec
+}
+
+// 设置登陆成功后切换的编解码器
+// codecObj：编解码器（为nil表示取消切换）
+func (c *Client) setPendingCodec(codecObj Codec) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.pendingCodec = codecObj
+}
+
+// 如果是登陆成功的响应，则切换到登陆时指定的编解码器（调用方需持有锁）
+// responseObj：待发送的响应对象
+func (c *Client) applyPendingCodec(responseObj *PreparedResponse) {
+	if c.pendingCodec == nil {
+		return
+	}
+
+	if obj := responseObj.GetResponseObject(); obj.CommandType == commandType.Login && obj.Code == serverResponseObject.Con_Success {
+		c.codec = c.pendingCodec
+		c.pendingCodec = nil
+	}
+}
+
 // 获取远程地址（IP_Port）
 func (clientObj *Client) getRemoteAddr() string {
 	items := strings.Split(clientObj.conn.RemoteAddr().String(), ":")
@@ -91,10 +169,11 @@ func (clientObj *Client) getRemoteShortAddr() string {
 // 返回值：
 // 消息内容
 // 是否含有有效数据
-func (c *Client) getReceiveData() ([]byte, bool) {
+// 错误对象（包头中的长度不正确）
+func (c *Client) getReceiveData() ([]byte, bool, error) {
 	// 判断是否包含头部信息
 	if len(c.receiveData) < con_HEADER_LENGTH {
-		return nil, false
+		return nil, false, nil
 	}
 
 	// 获取头部信息
@@ -103,9 +182,14 @@ func (c *Client) getReceiveData() ([]byte, bool) {
 	// 将头部数据转换为内部的长度
 	contentLength := intAndBytesUtil.BytesToInt32(header, byterOrder)
 
+	// 判断长度是否合法，以免客户端通过超长的消息耗尽内存
+	if contentLength < 0 || contentLength > con_MaxMessageLength {
+		return nil, false, fmt.Errorf("消息长度:%d不正确，最大长度为：%d", contentLength, con_MaxMessageLength)
+	}
+
 	// 判断长度是否满足
 	if len(c.receiveData) < con_HEADER_LENGTH+int(contentLength) {
-		return nil, false
+		return nil, false, nil
 	}
 
 	// 提取消息内容
@@ -114,53 +198,61 @@ func (c *Client) getReceiveData() ([]byte, bool) {
 	// 将对应的数据截断，以得到新的数据
 	c.receiveData = c.receiveData[con_HEADER_LENGTH+contentLength:]
 
-	return content, true
+	return content, true, nil
 }
 
-// 获取待发送的数据
+// 获取所有待发送的数据（高优先级的数据在前，低优先级的数据在后）
 // 返回值：
-// 待发送数据项
-// 是否含有有效数据
-func (clientObj *Client) getSendData() (responseObj *serverResponseObject.ResponseObject, exists bool) {
+// 待发送数据项列表
+func (clientObj *Client) getAllSendData() (sendDataList []*sendDataItem) {
 	clientObj.mutex.Lock()
 	defer clientObj.mutex.Unlock()
 
 	// 如果没有数据则直接返回
-	if len(clientObj.sendData) == 0 {
+	if len(clientObj.sendData) == 0 && len(clientObj.sendData_LowPriority) == 0 {
 		return
 	}
 
-	// 取出第一条数据,并为返回值赋值
-	responseObj = clientObj.sendData[0]
-	exists = true
+	// 取出所有的数据
+	sendDataList = make([]*sendDataItem, 0, len(clientObj.sendData)+len(clientObj.sendData_LowPriority))
+	sendDataList = append(sendDataList, clientObj.sendData...)
+	sendDataList = append(sendDataList, clientObj.sendData_LowPriority...)
+	clientObj.isSending = true
 
 	// 删除已经取出的数据
-	clientObj.sendData = clientObj.sendData[1:]
+	clientObj.sendData = make([]*sendDataItem, 0, 16)
+	clientObj.sendData_LowPriority = make([]*sendDataItem, 0, 16)
+	clientObj.checkHighWaterMark()
 
 	return
 }
 
-// 获取低优先级待发送的数据
+// 获取待发送数据的数量
 // 返回值：
-// 待发送数据项
-// 是否含有有效数据
-func (clientObj *Client) getSendData_LowPriority() (sendDataItemObj *serverResponseObject.ResponseObject, exists bool) {
+// 待发送数据的数量
+func (clientObj *Client) getSendDataCount() int {
 	clientObj.mutex.Lock()
 	defer clientObj.mutex.Unlock()
 
-	// 如果没有数据则直接返回
-	if len(clientObj.sendData_LowPriority) == 0 {
-		return
-	}
+	return len(clientObj.sendData) + len(clientObj.sendData_LowPriority)
+}
 
-	// 取出第一条数据,并为返回值赋值
-	sendDataItemObj = clientObj.sendData_LowPriority[0]
-	exists = true
+// 发送结束（无论成功与否）
+func (clientObj *Client) sendFinished() {
+	clientObj.mutex.Lock()
+	defer clientObj.mutex.Unlock()
 
-	// 删除已经取出的数据
-	clientObj.sendData_LowPriority = clientObj.sendData_LowPriority[1:]
+	clientObj.isSending = false
+}
 
-	return
+// 是否还有尚未发送完成的数据
+// 返回值：
+// 是否还有尚未发送完成的数据
+func (clientObj *Client) hasPendingSendData() bool {
+	clientObj.mutex.Lock()
+	defer clientObj.mutex.Unlock()
+
+	return clientObj.isSending || len(clientObj.sendData) > 0 || len(clientObj.sendData_LowPriority) > 0
 }
 
 // 获取连接状态
@@ -171,21 +263,94 @@ func (clientObj *Client) getConnStatus() ConnStatus {
 // 设置连接状态
 func (clientObj *Client) setConnStatus(status ConnStatus) {
 	clientObj.connStatus = status
+
+	// 唤醒发送Goroutine，以便其及时退出
+	clientObj.notifySend()
+}
+
+// 唤醒发送Goroutine（如果已有未处理的信号，则不再重复写入）
+func (clientObj *Client) notifySend() {
+	select {
+	case clientObj.sendSignal <- struct{}{}:
+	default:
+	}
 }
 
 // 追加发送的数据
-// sendDataItemObj:待发送数据项
+// responseObj:待发送数据项（可被多个客户端共享）
 // priority:优先级
 // 返回值：无
-func (clientObj *Client) appendSendData(responseObj *serverResponseObject.ResponseObject, priority Priority) {
+func (clientObj *Client) appendSendData(responseObj *PreparedResponse, priority Priority) {
 	clientObj.mutex.Lock()
 	defer clientObj.mutex.Unlock()
 
+	// 登陆成功的响应开始使用登陆时指定的编解码器
+	clientObj.applyPendingCodec(responseObj)
+
+	itemObj := &sendDataItem{
+		responseObj: responseObj,
+		codecObj:    clientObj.codec,
+	}
 	if priority == Con_LowPriority {
-		clientObj.sendData_LowPriority = append(clientObj.sendData_LowPriority, responseObj)
+		clientObj.sendData_LowPriority = append(clientObj.sendData_LowPriority, itemObj)
 	} else {
-		clientObj.sendData = append(clientObj.sendData, responseObj)
+		clientObj.sendData = append(clientObj.sendData, itemObj)
+	}
+
+	// 超过最大长度则丢弃数据：优先丢弃最早的低优先级数据，然后才丢弃最早的高优先级数据
+	// 有回调的数据（如私聊消息，回调用于发送送达回执）不丢弃，以免发送者等待超时后收到错误的失败回执；此时允许超过最大长度，由高水位检查驱逐
+	if maxSendQueueLength > 0 {
+		for len(clientObj.sendData)+len(clientObj.sendData_LowPriority) > maxSendQueueLength {
+			if len(clientObj.sendData_LowPriority) > 0 {
+				clientObj.sendData_LowPriority = clientObj.sendData_LowPriority[1:]
+			} else if index := clientObj.getDroppableSendDataIndex(); index >= 0 {
+				clientObj.sendData = append(clientObj.sendData[:index], clientObj.sendData[index+1:]...)
+			} else {
+				break
+			}
+
+			clientObj.droppedCount++
+		}
+	}
+
+	// 判断是否持续超过高水位，如果超过的时间太长，则驱逐该客户端
+	if clientObj.checkHighWaterMark() && !clientObj.isEvicted {
+		if time.Now().Sub(clientObj.overHighWaterMarkTime) > slowClientTimeout {
+			clientObj.isEvicted = true
+			go evictSlowClient(clientObj, len(clientObj.sendData)+len(clientObj.sendData_LowPriority), clientObj.droppedCount)
+		}
+	}
+
+	clientObj.notifySend()
+}
+
+// 获取最早的可以丢弃的高优先级数据的索引（调用方需持有锁）
+// 返回值：
+// 索引（没有可以丢弃的数据时为-1）
+func (clientObj *Client) getDroppableSendDataIndex() int {
+	for index, item := range clientObj.sendData {
+		if !item.responseObj.hasSentCallback() {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// 判断待发送数据是否超过高水位，并记录开始超过的时间（调用方需持有锁）
+// 返回值：
+// 是否超过高水位
+func (clientObj *Client) checkHighWaterMark() bool {
+	if sendQueueHighWaterMark <= 0 || len(clientObj.sendData)+len(clientObj.sendData_LowPriority) < sendQueueHighWaterMark {
+		clientObj.overHighWaterMarkTime = time.Time{}
+		return false
+	}
+
+	if clientObj.overHighWaterMarkTime.IsZero() {
+		clientObj.overHighWaterMarkTime = time.Now()
 	}
+
+	return true
 }
 
 // 追加接收到的数据
@@ -196,39 +361,103 @@ func (clientObj *Client) appendReceiveData(receiveData []byte) {
 	clientObj.activeTime = time.Now()
 }
 
-// 发送字节数组消息
-// responseObj:返回值对象
-func (clientObj *Client) sendMessage(responseObj *serverResponseObject.ResponseObject) error {
-	beforeTime := time.Now().Unix()
-
-	//序列化发送的数据
-	content, err := json.Marshal(responseObj)
-	if err != nil {
-		logUtil.Log("序列化response数据失败", logUtil.Error, true)
-		return errors.New("序列化response数据失败")
-	}
+// 收到WebSocket消息
+// messageType：帧类型
+// 返回值：无
+func (clientObj *Client) receiveWebSocketMessage(messageType int) {
+	atomic.StoreInt32(&clientObj.webSocketMessageType, int32(messageType))
+	clientObj.activeTime = time.Now()
+}
 
-	// 获得数据内容的长度
-	contentLength := len(content)
+// 发送字节数组消息（TCP连接将所有消息合并为一次写入；WebSocket连接每条消息为一帧）
+// sendDataList:待发送的数据项列表
+func (clientObj *Client) sendMessage(sendDataList []*sendDataItem) error {
+	defer clientObj.sendFinished()
 
-	// 将长度转化为字节数组
-	header := intAndBytesUtil.Int32ToBytes(int32(contentLength), byterOrder)
+	beforeTime := time.Now().Unix()
 
-	// 将头部与内容组合在一起
-	message := append(header, content...)
+	// 从缓冲池中获取缓冲区，用完之后放回
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	frameCount := 0
+	bufferedList := make([]*PreparedResponse, 0, len(sendDataList))
+	for _, item := range sendDataList {
+		//序列化发送的数据（广播时多个客户端共享同一份序列化结果）；序列化失败的数据直接丢弃
+		responseObj := item.responseObj
+		content, err := responseObj.encode(item.codecObj)
+		if err != nil {
+			logUtil.Log(fmt.Sprintf("序列化response数据失败，错误信息为：%s", err), logUtil.Error, true)
+			continue
+		}
+
+		// WebSocket以帧为单位，不需要包头，直接发送
+		if clientObj.connType == con_WebSocket {
+			messageType := int(atomic.LoadInt32(&clientObj.webSocketMessageType))
+			if item.codecObj.IsBinary() {
+				messageType = websocket.BinaryMessage
+			}
+
+			if err = clientObj.webSocketConn.WriteMessage(messageType, content); err != nil {
+				logUtil.Log(fmt.Sprintf("发送消息,%s,出现错误：%s", content, err), logUtil.Error, true)
+				return err
+			}
+
+			clientObj.addSentStatistics(len(content), 1)
+			responseObj.sent(clientObj)
+			continue
+		}
+
+		// 将长度转化为字节数组，并将头部与内容写入缓冲区
+		var header [con_HEADER_LENGTH]byte
+		byterOrder.PutUint32(header[:], uint32(len(content)))
+		buffer.Write(header[:])
+		buffer.Write(content)
+		bufferedList = append(bufferedList, responseObj)
+		frameCount++
+	}
 
-	// 发送消息
-	if _, err = clientObj.conn.Write(message); err != nil {
-		logUtil.Log(fmt.Sprintf("发送消息,%s,出现错误：%s", content, err), logUtil.Error, true)
-		return err
+	// 将缓冲区中的所有消息一次性发送
+	if buffer.Len() > 0 {
+		if _, err := clientObj.conn.Write(buffer.Bytes()); err != nil {
+			logUtil.Log(fmt.Sprintf("发送消息,数量:%d,Size:%d,出现错误：%s", frameCount, buffer.Len(), err), logUtil.Error, true)
+			return err
+		}
+
+		clientObj.addSentStatistics(buffer.Len(), frameCount)
+		for _, responseObj := range bufferedList {
+			responseObj.sent(clientObj)
+		}
 	}
 
 	// 如果发送的时间超过3秒，则记录下来
 	if time.Now().Unix()-beforeTime > 3 {
-		logUtil.Log(fmt.Sprintf("消息Size:%d, UseTime:%d", contentLength, time.Now().Unix()-beforeTime), logUtil.Warn, true)
+		logUtil.Log(fmt.Sprintf("消息数量:%d, Size:%d, UseTime:%d", len(sendDataList), buffer.Len(), time.Now().Unix()-beforeTime), logUtil.Warn, true)
 	}
 
-	return err
+	return nil
+}
+
+// 累加已发送的统计数据
+// byteCount：字节数
+// frameCount：消息数
+func (clientObj *Client) addSentStatistics(byteCount, frameCount int) {
+	atomic.AddInt64(&clientObj.sentBytes, int64(byteCount))
+	atomic.AddInt64(&clientObj.sentFrames, int64(frameCount))
+}
+
+// 获取已发送的字节数
+// 返回值：
+// 已发送的字节数
+func (clientObj *Client) GetSentBytes() int64 {
+	return atomic.LoadInt64(&clientObj.sentBytes)
+}
+
+// 获取已发送的消息数
+// 返回值：
+// 已发送的消息数
+func (clientObj *Client) GetSentFrames() int64 {
+	return atomic.LoadInt64(&clientObj.sentFrames)
 }
 
 // 判断客户端是否超时（超过300秒不活跃算作超时）
@@ -292,11 +521,26 @@ func newClient(_conn net.Conn) *Client {
 	return &Client{
 		id:                   getIncrementId(),
 		conn:                 _conn,
+		connType:             con_Tcp,
+		codec:                defaultCodec,
 		connStatus:           con_Open,
 		receiveData:          make([]byte, 0, 1024),
-		sendData:             make([]*serverResponseObject.ResponseObject, 0, 16),
-		sendData_LowPriority: make([]*serverResponseObject.ResponseObject, 0, 16),
+		sendData:             make([]*sendDataItem, 0, 16),
+		sendData_LowPriority: make([]*sendDataItem, 0, 16),
+		sendSignal:           make(chan struct{}, 1),
 		activeTime:           time.Now(),
 		playerId:             "",
 	}
 }
+
+// 新建WebSocket客户端对象
+// conn：WebSocket连接对象
+// 返回值：客户端对象的指针
+func newWebSocketClient(_conn *websocket.Conn) *Client {
+	clientObj := newClient(_conn.UnderlyingConn())
+	clientObj.connType = con_WebSocket
+	clientObj.webSocketConn = _conn
+	clientObj.webSocketMessageType = int32(websocket.TextMessage)
+
+	return clientObj
+}
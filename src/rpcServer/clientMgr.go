@@ -1,7 +1,10 @@
 package rpcServer
 
 import (
+	"fmt"
 	"sync"
+
+	"github.com/Jordanzuo/goutil/logUtil"
 )
 
 var (
@@ -25,9 +28,17 @@ func registerClient(clientObj *Client) {
 // clientObj：客户端对象
 func UnRegisterClient(clientObj *Client) {
 	mutex.Lock()
-	defer mutex.Unlock()
-
+	_, exists := clientMap[clientObj.GetId()]
 	delete(clientMap, clientObj.GetId())
+	mutex.Unlock()
+
+	// 同一个客户端可能被多次移除（如驱逐之后接收Goroutine退出时），只在第一次时记录发送的统计数据
+	if exists {
+		sentBytes, sentFrames := clientObj.GetSentBytes(), clientObj.GetSentFrames()
+		clientSentBytesHistogram.Observe(float64(sentBytes))
+		clientSentFramesHistogram.Observe(float64(sentFrames))
+		logUtil.Log(fmt.Sprintf("客户端断开连接，client:%s, 已发送字节数:%d, 已发送消息数:%d", clientObj, sentBytes, sentFrames), logUtil.Debug, true)
+	}
 }
 
 // 根据客户端Id获取对应的客户端对象
@@ -54,6 +65,20 @@ func GetClientCount() int {
 	return len(clientMap)
 }
 
+// 获取所有的客户端列表
+// 返回值：
+// 客户端列表
+func getClientList() (clientList []*Client) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, item := range clientMap {
+		clientList = append(clientList, item)
+	}
+
+	return
+}
+
 // 返回过期的客户端列表
 // 返回值：
 // 过期的客户端列表
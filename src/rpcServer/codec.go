@@ -0,0 +1,119 @@
+package rpcServer
+
+import (
+	"encoding/json"
+
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// JSON编解码器的名称（默认）
+	Con_Codec_JSON = "json"
+
+	// MessagePack编解码器的名称
+	Con_Codec_MsgPack = "msgpack"
+)
+
+// 请求、响应内容的编解码器
+type Codec interface {
+	// 名称
+	Name() string
+
+	// 序列化
+	Marshal(v interface{}) ([]byte, error)
+
+	// 反序列化
+	Unmarshal(data []byte, v interface{}) error
+
+	// 反序列化客户端请求，得到命令类型以及尚未解析的Command内容
+	UnmarshalRequest(data []byte) (commandType.CommandType, []byte, error)
+
+	// 是否为二进制格式（WebSocket据此选择帧类型）
+	IsBinary() bool
+}
+
+var (
+	// 支持的编解码器集合
+	codecMap = map[string]Codec{
+		Con_Codec_JSON:    new(jsonCodec),
+		Con_Codec_MsgPack: new(msgPackCodec),
+	}
+
+	// 默认的编解码器
+	defaultCodec = codecMap[Con_Codec_JSON]
+)
+
+// 根据名称获取编解码器
+// name：编解码器名称
+// 返回值：
+// 编解码器
+// 是否存在
+func getCodec(name string) (Codec, bool) {
+	codecObj, exists := codecMap[name]
+	return codecObj, exists
+}
+
+// JSON编解码器
+type jsonCodec struct{}
+
+func (c *jsonCodec) Name() string {
+	return Con_Codec_JSON
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (c *jsonCodec) UnmarshalRequest(data []byte) (commandType.CommandType, []byte, error) {
+	var requestObj struct {
+		CommandType commandType.CommandType
+		Command     json.RawMessage
+	}
+
+	if err := json.Unmarshal(data, &requestObj); err != nil {
+		return 0, nil, err
+	}
+
+	return requestObj.CommandType, requestObj.Command, nil
+}
+
+func (c *jsonCodec) IsBinary() bool {
+	return false
+}
+
+// MessagePack编解码器
+type msgPackCodec struct{}
+
+func (c *msgPackCodec) Name() string {
+	return Con_Codec_MsgPack
+}
+
+func (c *msgPackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (c *msgPackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (c *msgPackCodec) UnmarshalRequest(data []byte) (commandType.CommandType, []byte, error) {
+	var requestObj struct {
+		CommandType commandType.CommandType
+		Command     msgpack.RawMessage
+	}
+
+	if err := msgpack.Unmarshal(data, &requestObj); err != nil {
+		return 0, nil, err
+	}
+
+	return requestObj.CommandType, requestObj.Command, nil
+}
+
+func (c *msgPackCodec) IsBinary() bool {
+	return true
+}
@@ -1,15 +1,18 @@
 package rpcServer
 
 import (
-	"github.com/Jordanzuo/ChatServerModel/src/channelType"
+	"time"
+
 	"github.com/Jordanzuo/ChatServerModel/src/player"
-	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
 )
 
 var (
 	// 聊天服务器监听地址
 	chatServerListenAddress string
 
+	// WebSocket监听地址（为空则不启动WebSocket服务器）
+	webSocketListenAddress string
+
 	//查找player方法
 	getPlayer func(string, bool) (*player.Player, bool, error)
 
@@ -19,41 +22,38 @@ var (
 	//查找disconnectByClient方法
 	disconnectByClient func(*Client)
 
-	// 登陆处理器
-	loginHandler func(*Client, string, string, string, string, string, int, int) *serverResponseObject.ResponseObject
-
-	// 登出处理器
-	logoutHandler func(*Client, *player.Player) *serverResponseObject.ResponseObject
+	// 每个客户端待发送数据的最大数量（超过则丢弃数据，<=0表示不限制）
+	maxSendQueueLength int
 
-	// 更新玩家信息处理器
-	updatePlayerInfoHandler func(*Client, *player.Player, string, string, string) *serverResponseObject.ResponseObject
+	// 每个客户端待发送数据的高水位（<=0表示不检查）
+	sendQueueHighWaterMark int
 
-	// 发送消息处理器
-	sendMessageHandler func(*Client, *player.Player, channelType.ChannelType, string, string) *serverResponseObject.ResponseObject
+	// 待发送数据持续超过高水位的最长时间（超过则断开连接）
+	slowClientTimeout time.Duration
 
 	// 是否测试
 	debug bool
 )
 
-//传递上层函数地址
+//传递上层函数地址（命令处理器通过RegisterHandler注册）
 func SetConfig(_chatServerListenAddress string,
+	_webSocketListenAddress string,
 	_getPlayer func(string, bool) (*player.Player, bool, error),
 	_getPlayerCount func() int,
 	_disconnectByClient func(*Client),
-	_loginHandler func(*Client, string, string, string, string, string, int, int) *serverResponseObject.ResponseObject,
-	_logoutHandler func(*Client, *player.Player) *serverResponseObject.ResponseObject,
-	_updatePlayerInfoHandler func(*Client, *player.Player, string, string, string) *serverResponseObject.ResponseObject,
-	_sendMessageHandler func(*Client, *player.Player, channelType.ChannelType, string, string) *serverResponseObject.ResponseObject,
+	_maxSendQueueLength int,
+	_sendQueueHighWaterMark int,
+	_slowClientTimeout time.Duration,
 	_debug bool) {
 
 	// 为配置赋值
 	chatServerListenAddress = _chatServerListenAddress
+	webSocketListenAddress = _webSocketListenAddress
 	getPlayer = _getPlayer
 	getPlayerCount = _getPlayerCount
 	disconnectByClient = _disconnectByClient
-	loginHandler = _loginHandler
-	logoutHandler = _logoutHandler
-	updatePlayerInfoHandler = _updatePlayerInfoHandler
-	sendMessageHandler = _sendMessageHandler
+	maxSendQueueLength = _maxSendQueueLength
+	sendQueueHighWaterMark = _sendQueueHighWaterMark
+	slowClientTimeout = _slowClientTimeout
 	debug = _debug
 }
@@ -0,0 +1,12 @@
+package rpcServer
+
+// 客户端连接类型
+type ConnType int
+
+const (
+	// TCP连接（4字节小端长度包头 + 内容）
+	con_Tcp ConnType = 1 + iota
+
+	// WebSocket连接（每一帧为一条完整的消息）
+	con_WebSocket
+)
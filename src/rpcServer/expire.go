@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Jordanzuo/ChatServer/src/model"
 	"github.com/Jordanzuo/goutil/logUtil"
 )
 
@@ -38,3 +39,20 @@ func clearExpiredClient() {
 		logUtil.Log(fmt.Sprintf("清理前的客户端数量为：%d，清理前的玩家数量为：%d， 本次清理不活跃的数量为：%d", beforeClientCount, beforePlayerCount, expiredClientCount), logUtil.Debug, true)
 	}
 }
+
+// 驱逐接收过慢的客户端（待发送数据持续超过高水位）
+// clientObj：客户端对象
+// queueLength：待发送数据的数量
+// droppedCount：已丢弃的数据数量
+func evictSlowClient(clientObj *Client, queueLength, droppedCount int) {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	logUtil.Log(fmt.Sprintf("客户端接收过慢，断开连接。DisconnectType:%d, client:%s, 待发送数量:%d, 已丢弃数量:%d", model.Con_FromSlowConsumer, clientObj, queueLength, droppedCount), logUtil.Warn, true)
+
+	disconnectByClient(clientObj)
+}
@@ -1,13 +1,10 @@
 package rpcServer
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"net"
-	"time"
 
-	"github.com/Jordanzuo/ChatServerModel/src/channelType"
 	"github.com/Jordanzuo/ChatServerModel/src/commandType"
 	"github.com/Jordanzuo/ChatServerModel/src/player"
 	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
@@ -33,45 +30,38 @@ func handleSendData(clientObj *Client) {
 		//是否是最后一条消息
 		connStatus := clientObj.getConnStatus()
 
-		// 是否被处理过
-		handled := false
-
-		// 优先处理高优先级的数据，如果发送出现错误，表示连接已经断开，则退出方法；如果没有待处理的数据，则退出循环
-		for {
-			if responseObject, exists := clientObj.getSendData(); exists {
-				handled = true
-				if err := clientObj.sendMessage(responseObject); err != nil {
-					return
-				}
-			} else {
-				break
-			}
-		}
-
-		// 当没有高优先级的数据时才处理低优先级的数据，如果发送出现错误，表示连接已经断开，则退出方法；
-		if responseObject, exists := clientObj.getSendData_LowPriority(); exists {
-			handled = true
-			if err := clientObj.sendMessage(responseObject); err != nil {
+		// 一次取出所有待发送的数据（高优先级的在前），合并发送；如果发送出现错误，表示连接已经断开，则退出方法
+		if sendDataList := clientObj.getAllSendData(); len(sendDataList) > 0 {
+			if err := clientObj.sendMessage(sendDataList); err != nil {
 				return
 			}
+
+			// 发送期间可能有新的数据，继续处理
+			continue
 		}
 
-		// 如果本轮没有被处理过，则休眠5ms
-		if !handled {
-			time.Sleep(5 * time.Millisecond)
-			if connStatus == con_WaitForClose {
-				clientObj.setConnStatus(con_Close)
-			}
+		// 如果已经没有待发送的数据，且是最后一条消息，则关闭
+		if connStatus == con_WaitForClose {
+			clientObj.setConnStatus(con_Close)
+			continue
 		}
+
+		// 阻塞直到有新的待发送数据或连接状态变化
+		<-clientObj.sendSignal
 	}
 }
 
 // 处理客户端收到的数据
 // clientObj：客户端对象
-func handleReceiveData(clientObj *Client) {
+// 返回值：
+// 错误对象（消息长度不正确，需要断开连接）
+func handleReceiveData(clientObj *Client) error {
 	for {
 		// 获取有效的消息
-		message, exists := clientObj.getReceiveData()
+		message, exists, err := clientObj.getReceiveData()
+		if err != nil {
+			return err
+		}
 		if !exists {
 			break
 		}
@@ -84,6 +74,8 @@ func handleReceiveData(clientObj *Client) {
 			handleRequest(clientObj, message)
 		}
 	}
+
+	return nil
 }
 
 // 处理客户端连接
@@ -131,13 +123,16 @@ func handleConn(conn net.Conn) {
 		clientObj.appendReceiveData(readBytes[:n])
 
 		// 处理数据
-		handleReceiveData(clientObj)
+		if err = handleReceiveData(clientObj); err != nil {
+			clientObj.WriteLog(fmt.Sprintf("处理消息错误：%s", err))
+			break
+		}
 	}
 }
 
 // 处理客户端请求
 // clientObj：对应的客户端对象
-// request：请求内容字节数组(json格式)
+// request：请求内容字节数组(格式由客户端的编解码器决定)
 // 返回值：无
 func handleRequest(clientObj *Client, request []byte) {
 	responseObj := serverResponseObject.NewResponseObject(commandType.Login)
@@ -145,6 +140,9 @@ func handleRequest(clientObj *Client, request []byte) {
 	defer func() {
 		// 如果不成功，则向客户端发送数据；因为成功已经通过对应的方法发送结果，故不通过此处
 		if responseObj.Code != serverResponseObject.Con_Success {
+			// 记录指标
+			incRejectedMessage(responseObj.Code)
+
 			// 如果是客户端数据错误，则将客户端请求数据记录下来
 			if responseObj.Code == serverResponseObject.Con_ClientDataError {
 				logUtil.Log(fmt.Sprintf("请求的数据为：%s, 返回的结果为客户端数据错误", string(request)), logUtil.Error, true)
@@ -156,45 +154,32 @@ func handleRequest(clientObj *Client, request []byte) {
 	}()
 
 	// 定义变量
-	var requestMap map[string]interface{}
-	var commandMap map[string]interface{}
 	var playerObj *player.Player
-	var id string
-	var name string
-	var unionId string
-	var extraMsg string
-	var sign string
-	var partnerId int
-	var serverId int
-	var message string
-	var toPlayerId string
-	var _commandType commandType.CommandType
-	var _channelType channelType.ChannelType
+	var requestObj interface{}
 	var exists bool
-	var ok bool
 	var err error
 
-	// 解析请求字符串
-	if err = json.Unmarshal(request, &requestMap); err != nil {
+	// 解析请求字符串，得到CommandType以及尚未解析的Command
+	_commandType, command, err := clientObj.getCodec().UnmarshalRequest(request)
+	if err != nil {
 		logUtil.Log(fmt.Sprintf("反序列化出错，错误信息为：%s", err), logUtil.Error, true)
 		responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
 		return
 	}
 
-	// 解析CommandType
-	if commandType_float, ok := requestMap["CommandType"].(float64); !ok {
-		logUtil.Log("CommandType不是int类型", logUtil.Error, true)
-		responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-		return
-	} else {
-		_commandType = commandType.CommandType(int(commandType_float))
-	}
-
 	// 设置responseObject的CommandType
 	responseObj.SetCommandType(_commandType)
 
-	// 如果不是Login方法，则判断Client对象所对应的玩家对象是否存在（因为当是Login方法时，Player对象尚不存在）
-	if _commandType != commandType.Login {
+	// 获取命令对应的处理器
+	handlerObj, exists := getHandler(_commandType)
+	if !exists {
+		logUtil.Log(fmt.Sprintf("未找到该方法:%d", _commandType), logUtil.Error, true)
+		responseObj.SetResultStatus(serverResponseObject.Con_CommandTypeNotDefined)
+		return
+	}
+
+	// 如果需要登陆，则判断Client对象所对应的玩家对象是否存在（因为当是Login方法时，Player对象尚不存在）
+	if handlerObj.needLogin {
 		if clientObj.GetPlayerId() == "" {
 			responseObj.SetResultStatus(serverResponseObject.Con_NoLogin)
 			return
@@ -212,114 +197,37 @@ func handleRequest(clientObj *Client, request []byte) {
 		}
 	}
 
-	// 解析Command(是map[string]interface{}类型)；只有当不是Logout方法时才解析，因为Logout时Command为空
-	if _commandType != commandType.Logout {
-		if commandMap, ok = requestMap["Command"].(map[string]interface{}); !ok {
-			logUtil.Log("commandMap不是map类型", logUtil.Error, true)
+	// 解析Command；没有参数的命令（如Logout）不解析
+	if handlerObj.newRequest != nil {
+		if requestObj, err = handlerObj.decode(clientObj.getCodec(), command); err != nil {
+			logUtil.Log(fmt.Sprintf("解析CommandType:%d的参数出错，错误信息为：%s", _commandType, err), logUtil.Error, true)
 			responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
 			return
 		}
+	}
 
-		// 解析出详细的参数
-		if id_interface, exists := commandMap["Id"]; exists {
-			if id, ok = id_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("id:%v不是string类型", id_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
-		}
-
-		if name_interface, exists := commandMap["Name"]; exists {
-			if name, ok = name_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("name:%v不是string类型", name_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
-		}
-
-		if unionId_interface, exists := commandMap["UnionId"]; exists {
-			if unionId, ok = unionId_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("unionId:%v不是string类型", unionId_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
-		}
-
-		if extraMsg_interface, exists := commandMap["ExtraMsg"]; exists {
-			if extraMsg, ok = extraMsg_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("extraMsg:%v不是string类型", extraMsg_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
-		}
-
-		if sign_interface, exists := commandMap["Sign"]; exists {
-			if sign, ok = sign_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("sign:%v不是string类型", sign_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
-		}
-
-		if partnerId_interface, exists := commandMap["PartnerId"]; exists {
-			if partnerId_float64, ok := partnerId_interface.(float64); !ok {
-				logUtil.Log(fmt.Sprintf("partnerId:%v不是float64类型", partnerId_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			} else {
-				partnerId = int(partnerId_float64)
-			}
-		}
-
-		if serverId_interface, exists := commandMap["ServerId"]; exists {
-			if serverId_float64, ok := serverId_interface.(float64); !ok {
-				logUtil.Log(fmt.Sprintf("serverId:%v不是float64类型", serverId_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			} else {
-				serverId = int(serverId_float64)
-			}
-		}
-
-		if channelType_interface, exists := commandMap["ChannelType"]; exists {
-			if channelType_float64, ok := channelType_interface.(float64); !ok {
-				logUtil.Log(fmt.Sprintf("channelType:%v不是float64类型", channelType_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			} else {
-				_channelType = channelType.ChannelType(channelType_float64)
-			}
+	// 如果客户端在登陆时指定了编解码器，则从登陆成功的响应开始使用该编解码器（登陆失败则不切换）
+	if _commandType == commandType.Login {
+		codecRequestObj := new(codecRequest)
+		if err = clientObj.getCodec().Unmarshal(command, codecRequestObj); err != nil {
+			logUtil.Log(fmt.Sprintf("解析Codec出错，错误信息为：%s", err), logUtil.Error, true)
+			responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
+			return
 		}
 
-		if message_interface, exists := commandMap["Message"]; exists {
-			if message, ok = message_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("message:%v不是string类型", message_interface), logUtil.Error, true)
+		if codecRequestObj.Codec != "" {
+			codecObj, exists := getCodec(codecRequestObj.Codec)
+			if !exists {
+				logUtil.Log(fmt.Sprintf("codec:%s未定义", codecRequestObj.Codec), logUtil.Error, true)
 				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
 				return
 			}
-		}
 
-		if toPlayerId_interface, exists := commandMap["ToPlayerId"]; exists {
-			if toPlayerId, ok = toPlayerId_interface.(string); !ok {
-				logUtil.Log(fmt.Sprintf("toPlayerId:%v不是string类型", toPlayerId_interface), logUtil.Error, true)
-				responseObj.SetResultStatus(serverResponseObject.Con_ClientDataError)
-				return
-			}
+			clientObj.setPendingCodec(codecObj)
+			defer clientObj.setPendingCodec(nil)
 		}
 	}
 
 	// 调用方法
-	switch _commandType {
-	case commandType.Login:
-		responseObj = loginHandler(clientObj, id, name, unionId, extraMsg, sign, partnerId, serverId)
-	case commandType.Logout:
-		responseObj = logoutHandler(clientObj, playerObj)
-	case commandType.UpdatePlayerInfo:
-		responseObj = updatePlayerInfoHandler(clientObj, playerObj, name, unionId, extraMsg)
-	case commandType.SendMessage:
-		responseObj = sendMessageHandler(clientObj, playerObj, _channelType, message, toPlayerId)
-	default:
-		logUtil.Log("未找到该方法", logUtil.Error, true)
-		responseObj.SetResultStatus(serverResponseObject.Con_CommandTypeNotDefined)
-	}
+	responseObj = handlerObj.handleFunc(clientObj, playerObj, requestObj)
 }
@@ -0,0 +1,100 @@
+package rpcServer
+
+import (
+	"fmt"
+
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/player"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 命令处理方法
+// clientObj：客户端对象
+// playerObj：玩家对象（不需要登陆的命令为nil）
+// requestObj：解析后的请求参数（由注册时的newRequest创建；没有参数的命令为nil）
+// 返回值：
+// 响应对象
+type HandleFunc func(clientObj *Client, playerObj *player.Player, requestObj interface{}) *serverResponseObject.ResponseObject
+
+// 请求参数的校验接口（请求参数对象可选择实现）
+type Validator interface {
+	// 校验请求参数
+	// 返回值：
+	// 错误对象（不为nil则表示参数不正确）
+	Validate() error
+}
+
+// 命令处理器
+type handler struct {
+	// 是否需要登陆
+	needLogin bool
+
+	// 创建请求参数对象的方法（返回结构体指针；为nil表示该命令没有参数）
+	newRequest func() interface{}
+
+	// 处理方法
+	handleFunc HandleFunc
+}
+
+// 解析请求参数
+// codecObj：编解码器
+// command：尚未解析的Command内容
+// 返回值：
+// 请求参数对象
+// 错误对象
+func (h *handler) decode(codecObj Codec, command []byte) (interface{}, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("Command为空")
+	}
+
+	requestObj := h.newRequest()
+	if err := codecObj.Unmarshal(command, requestObj); err != nil {
+		return nil, err
+	}
+
+	if validatorObj, ok := requestObj.(Validator); ok {
+		if err := validatorObj.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return requestObj, nil
+}
+
+// 登陆时用于协商编解码器的参数
+type codecRequest struct {
+	// 编解码器名称
+	Codec string
+}
+
+var (
+	// 命令处理器集合（只在init时注册，之后只读，故不需要加锁）
+	handlerMap = make(map[commandType.CommandType]*handler, 16)
+)
+
+// 注册命令处理器
+// _commandType：命令类型
+// needLogin：是否需要登陆
+// newRequest：创建请求参数对象的方法（返回结构体指针；为nil表示该命令没有参数）
+// handleFunc：处理方法
+func RegisterHandler(_commandType commandType.CommandType, needLogin bool, newRequest func() interface{}, handleFunc HandleFunc) {
+	if _, exists := handlerMap[_commandType]; exists {
+		panic(fmt.Errorf("CommandType:%d的处理器已经注册过", _commandType))
+	}
+
+	handlerMap[_commandType] = &handler{
+		needLogin:  needLogin,
+		newRequest: newRequest,
+		handleFunc: handleFunc,
+	}
+}
+
+// 获取命令处理器
+// _commandType：命令类型
+// 返回值：
+// 命令处理器
+// 是否存在
+func getHandler(_commandType commandType.CommandType) (*handler, bool) {
+	handlerObj, exists := handlerMap[_commandType]
+	return handlerObj, exists
+}
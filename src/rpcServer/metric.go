@@ -0,0 +1,40 @@
+package rpcServer
+
+import (
+	"strconv"
+
+	"github.com/Jordanzuo/ChatServer/src/metric"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+var (
+	// 被拒绝的客户端消息数量（所有命令，按响应状态的值）
+	rejectedMessageCounter = metric.NewCounter("chat_messages_rejected_total", "被拒绝的客户端消息数量", "result_code")
+
+	// 每个客户端连接在断开时已发送的字节数、消息数
+	clientSentBytesHistogram  = metric.NewHistogram("chat_client_sent_bytes", "每个客户端连接断开时已发送的字节数", []float64{1 << 10, 1 << 14, 1 << 17, 1 << 20, 1 << 23, 1 << 26})
+	clientSentFramesHistogram = metric.NewHistogram("chat_client_sent_frames", "每个客户端连接断开时已发送的消息数", []float64{10, 100, 1000, 10000, 100000})
+)
+
+func init() {
+	// 注册客户端相关的指标
+	metric.RegisterGaugeFunc("chat_connected_clients", "当前连接的客户端数量", "", func() map[string]float64 {
+		return map[string]float64{"": float64(GetClientCount())}
+	})
+
+	metric.RegisterHistogramFunc("chat_send_queue_depth", "每个客户端待发送数据的数量", []float64{0, 1, 5, 10, 50, 100, 500, 1000}, func() []float64 {
+		clientList := getClientList()
+		valueList := make([]float64, 0, len(clientList))
+		for _, clientObj := range clientList {
+			valueList = append(valueList, float64(clientObj.getSendDataCount()))
+		}
+
+		return valueList
+	})
+}
+
+// 记录被拒绝的客户端消息
+// resultStatus：响应状态
+func incRejectedMessage(resultStatus serverResponseObject.ResultStatus) {
+	rejectedMessageCounter.Inc(strconv.Itoa(int(resultStatus)))
+}
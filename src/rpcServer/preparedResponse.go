@@ -0,0 +1,83 @@
+package rpcServer
+
+import (
+	"sync"
+
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+// 预先序列化的响应对象：同一个对象可以被加入多个客户端的待发送队列，每种编解码器只序列化一次
+// 序列化后的内容被所有客户端共享，故不能被修改
+type PreparedResponse struct {
+	// 响应对象（加入待发送队列之后不能再修改）
+	responseObj *serverResponseObject.ResponseObject
+
+	// 按编解码器名称缓存的序列化结果，及其锁对象
+	contentMap map[string][]byte
+	mutex      sync.Mutex
+
+	// 成功写入客户端连接之后的回调（在客户端的发送Goroutine中执行，故不能阻塞；为nil表示不需要回调）
+	sentCallback func(*Client)
+}
+
+// 获取响应对象
+// 返回值：
+// 响应对象
+func (p *PreparedResponse) GetResponseObject() *serverResponseObject.ResponseObject {
+	return p.responseObj
+}
+
+// 设置成功写入客户端连接之后的回调（必须在加入待发送队列之前设置）
+// callback：回调方法（参数为已写入的客户端对象；共享时每个客户端各回调一次）
+func (p *PreparedResponse) SetSentCallback(callback func(*Client)) {
+	p.sentCallback = callback
+}
+
+// 是否有成功写入客户端连接之后的回调
+// 返回值：
+// 是否有回调
+func (p *PreparedResponse) hasSentCallback() bool {
+	return p.sentCallback != nil
+}
+
+// 成功写入客户端连接之后调用
+// clientObj：客户端对象
+func (p *PreparedResponse) sent(clientObj *Client) {
+	if p.sentCallback != nil {
+		p.sentCallback(clientObj)
+	}
+}
+
+// 使用指定的编解码器序列化（同一编解码器只序列化一次）
+// codecObj：编解码器
+// 返回值：
+// 序列化后的内容（共享，不能修改）
+// 错误对象
+func (p *PreparedResponse) encode(codecObj Codec) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if content, exists := p.contentMap[codecObj.Name()]; exists {
+		return content, nil
+	}
+
+	content, err := codecObj.Marshal(p.responseObj)
+	if err != nil {
+		return nil, err
+	}
+
+	p.contentMap[codecObj.Name()] = content
+
+	return content, nil
+}
+
+// 新建预先序列化的响应对象
+// responseObj：响应对象
+// 返回值：
+// 预先序列化的响应对象
+func NewPreparedResponse(responseObj *serverResponseObject.ResponseObject) *PreparedResponse {
+	return &PreparedResponse{
+		responseObj: responseObj,
+		contentMap:  make(map[string][]byte, 2),
+	}
+}
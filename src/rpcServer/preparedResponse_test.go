@@ -0,0 +1,117 @@
+package rpcServer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Jordanzuo/ChatServerModel/src/commandType"
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+)
+
+var (
+	// 广播的客户端数量
+	benchmarkClientCountList = []int{100, 1000, 5000}
+)
+
+// 新建世界频道聊天消息的响应对象
+func newBenchmarkResponseObject() *serverResponseObject.ResponseObject {
+	return &serverResponseObject.ResponseObject{
+		Code:        serverResponseObject.Con_Success,
+		CommandType: commandType.SendMessage,
+		Data: map[string]interface{}{
+			"ChannelType": 1,
+			"MessageId":   "1024_1700000000000_1",
+			"Message":     "世界频道的聊天消息，Hello World!",
+			"FromPlayer": map[string]interface{}{
+				"Id":        "5d1c5d1a-3f0c-4b8e-9a55-0a1b2c3d4e5f",
+				"Name":      "玩家名称",
+				"UnionId":   "00000000-0000-0000-0000-000000000000",
+				"ExtraMsg":  `{"Vip":10,"Level":99}`,
+				"PartnerId": 1001,
+				"ServerId":  1,
+			},
+		},
+	}
+}
+
+// 新建广播的客户端列表
+// count：客户端数量
+// codecObj：编解码器
+func newBenchmarkClientList(count int, codecObj Codec) []*Client {
+	clientList := make([]*Client, 0, count)
+	for i := 0; i < count; i++ {
+		clientObj := newClient(nil)
+		clientObj.codec = codecObj
+		clientList = append(clientList, clientObj)
+	}
+
+	return clientList
+}
+
+// 模拟发送Goroutine：取出所有待发送的数据并序列化
+// clientList：客户端列表
+func drainBenchmarkClientList(b *testing.B, clientList []*Client) {
+	for _, clientObj := range clientList {
+		for _, item := range clientObj.getAllSendData() {
+			if _, err := item.responseObj.encode(item.codecObj); err != nil {
+				b.Fatal(err)
+			}
+		}
+		clientObj.sendFinished()
+	}
+}
+
+// 每个客户端各自序列化（优化之前的方式）
+func benchmarkBroadcastPerRecipient(b *testing.B, codecObj Codec) {
+	for _, count := range benchmarkClientCountList {
+		b.Run(fmt.Sprintf("Clients=%d", count), func(b *testing.B) {
+			clientList := newBenchmarkClientList(count, codecObj)
+			responseObj := newBenchmarkResponseObject()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, clientObj := range clientList {
+					ResponseResult(clientObj, responseObj, Con_HighPriority)
+				}
+				drainBenchmarkClientList(b, clientList)
+			}
+		})
+	}
+}
+
+// 所有客户端共享同一个PreparedResponse，只序列化一次（playerBLL.SendToPlayer的方式）
+func benchmarkBroadcastPrepared(b *testing.B, codecObj Codec) {
+	for _, count := range benchmarkClientCountList {
+		b.Run(fmt.Sprintf("Clients=%d", count), func(b *testing.B) {
+			clientList := newBenchmarkClientList(count, codecObj)
+			responseObj := newBenchmarkResponseObject()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				preparedObj := NewPreparedResponse(responseObj)
+				for _, clientObj := range clientList {
+					ResponseResultPrepared(clientObj, preparedObj, Con_HighPriority)
+				}
+				drainBenchmarkClientList(b, clientList)
+			}
+		})
+	}
+}
+
+func BenchmarkBroadcastPerRecipientJSON(b *testing.B) {
+	benchmarkBroadcastPerRecipient(b, codecMap[Con_Codec_JSON])
+}
+
+func BenchmarkBroadcastPreparedJSON(b *testing.B) {
+	benchmarkBroadcastPrepared(b, codecMap[Con_Codec_JSON])
+}
+
+func BenchmarkBroadcastPerRecipientMsgPack(b *testing.B) {
+	benchmarkBroadcastPerRecipient(b, codecMap[Con_Codec_MsgPack])
+}
+
+func BenchmarkBroadcastPreparedMsgPack(b *testing.B) {
+	benchmarkBroadcastPrepared(b, codecMap[Con_Codec_MsgPack])
+}
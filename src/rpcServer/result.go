@@ -10,5 +10,13 @@ import (
 // responseObject：响应对象（不能为指针类型，否则在registerFunction时判断类型会出错）
 // priority:优先级
 func ResponseResult(clientObj *Client, responseObj *serverResponseObject.ResponseObject, priority Priority) {
-	clientObj.appendSendData(responseObj, priority)
+	clientObj.appendSendData(NewPreparedResponse(responseObj), priority)
+}
+
+// 发送预先序列化的响应结果（用于广播：同一个preparedObj可发送给多个客户端，只序列化一次）
+// clientObj：客户端对象
+// preparedObj：预先序列化的响应对象
+// priority:优先级
+func ResponseResultPrepared(clientObj *Client, preparedObj *PreparedResponse, priority Priority) {
+	clientObj.appendSendData(preparedObj, priority)
 }
@@ -13,7 +13,10 @@ import (
 //此处开启的goroutine不需要捕获异常
 func StartServer(wg *sync.WaitGroup) {
 	defer func() {
-		wg.Done()
+		// 正常停止时由关闭流程负责退出程序，故不需要通知主线程
+		if !isStopping() {
+			wg.Done()
+		}
 	}()
 
 	logUtil.Log("Socket服务器开始监听...", logUtil.Info, true)
@@ -23,7 +26,13 @@ func StartServer(wg *sync.WaitGroup) {
 	if err != nil {
 		panic(errors.New(fmt.Sprintf("Listen Error: %s", err)))
 	} else {
-		msg := fmt.Sprintf("Got listener for the server. (local address: %s)", listener.Addr())
+		// 如果启用了TLS，则包装为TLS监听对象
+		listener = wrapTLSListener(listener)
+
+		// 记录监听对象，以便停止服务器时关闭
+		addListener(listener)
+
+		msg := fmt.Sprintf("Got listener for the server. (local address: %s, tls: %v)", listener.Addr(), isTLSEnabled())
 
 		// 记录和显示日志，并且判断是否需要退出
 		logUtil.Log(msg, logUtil.Info, true)
@@ -40,6 +49,12 @@ func StartServer(wg *sync.WaitGroup) {
 		// 阻塞直至新连接到来
 		conn, err := listener.Accept()
 		if err != nil {
+			// 如果是服务器停止导致的错误，则退出
+			if isStopping() {
+				logUtil.Log("Socket服务器停止监听", logUtil.Info, true)
+				return
+			}
+
 			logUtil.Log(fmt.Sprintf("Accept Error: %s", err), logUtil.Error, true)
 			continue
 		}
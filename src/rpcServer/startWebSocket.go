@@ -0,0 +1,176 @@
+package rpcServer
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Jordanzuo/goutil/logUtil"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// 允许的来源集合（为空则只允许同源或没有Origin的请求；包含*则允许所有来源）
+	webSocketAllowedOriginMap = make(map[string]bool)
+
+	// WebSocket升级对象
+	upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkWebSocketOrigin,
+	}
+)
+
+// 设置WebSocket配置
+// allowedOrigins：允许的来源（如https://h5.example.com，多个以逗号分隔；为空则只允许同源，*表示允许所有来源）
+func SetWebSocketConfig(allowedOrigins string) {
+	allowedOriginMap := make(map[string]bool)
+	for _, item := range strings.Split(allowedOrigins, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			allowedOriginMap[item] = true
+		}
+	}
+
+	webSocketAllowedOriginMap = allowedOriginMap
+}
+
+// 校验WebSocket升级请求的来源，以防止跨站WebSocket劫持
+// r：请求对象
+// 返回值：
+// 是否允许
+func checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+
+	// 非浏览器客户端（如原生客户端）不会发送Origin
+	if origin == "" {
+		return true
+	}
+
+	if webSocketAllowedOriginMap["*"] || webSocketAllowedOriginMap[strings.ToLower(origin)] {
+		return true
+	}
+
+	// 没有配置时只允许同源
+	if len(webSocketAllowedOriginMap) == 0 {
+		if index := strings.Index(origin, "://"); index >= 0 && strings.EqualFold(origin[index+3:], r.Host) {
+			return true
+		}
+	}
+
+	logUtil.Log(fmt.Sprintf("WebSocket请求的来源:%s不被允许，RemoteAddr:%s", origin, r.RemoteAddr), logUtil.Warn, true)
+
+	return false
+}
+
+// 启动WebSocket服务器
+//此处开启的goroutine不需要捕获异常
+func StartWebSocketServer(wg *sync.WaitGroup) {
+	defer func() {
+		// 正常停止时由关闭流程负责退出程序，故不需要通知主线程
+		if !isStopping() {
+			wg.Done()
+		}
+	}()
+
+	logUtil.Log("WebSocket服务器开始监听...", logUtil.Info, true)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handleWebSocketRequest)
+
+	// 监听指定的端口
+	listener, err := net.Listen("tcp", webSocketListenAddress)
+	if err != nil {
+		panic(fmt.Errorf("WebSocket Listen Error: %s", err))
+	} else {
+		// 如果启用了TLS，则包装为TLS监听对象
+		listener = wrapTLSListener(listener)
+
+		// 记录监听对象，以便停止服务器时关闭
+		addListener(listener)
+
+		msg := fmt.Sprintf("Got listener for the websocket server. (local address: %s, tls: %v)", listener.Addr(), isTLSEnabled())
+
+		// 记录和显示日志
+		logUtil.Log(msg, logUtil.Info, true)
+		fmt.Println(msg)
+	}
+
+	if err = http.Serve(listener, mux); err != nil {
+		// 如果是服务器停止导致的错误，则退出
+		if isStopping() {
+			logUtil.Log("WebSocket服务器停止监听", logUtil.Info, true)
+			return
+		}
+
+		panic(fmt.Errorf("WebSocket Serve Error: %s", err))
+	}
+}
+
+// 处理WebSocket的升级请求
+// w：响应对象
+// r：请求对象
+func handleWebSocketRequest(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logUtil.Log(fmt.Sprintf("WebSocket Upgrade Error: %s", err), logUtil.Error, true)
+		return
+	}
+
+	handleWebSocketConn(conn)
+}
+
+// 处理WebSocket客户端连接
+// conn：WebSocket连接对象
+func handleWebSocketConn(conn *websocket.Conn) {
+	// 处理内部未处理的异常，以免导致主线程退出，从而导致系统崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			logUtil.LogUnknownError(r)
+		}
+	}()
+
+	// 限制每一帧的长度，与TCP连接的消息长度限制一致（超过时ReadMessage返回错误，从而断开连接）
+	conn.SetReadLimit(con_MaxMessageLength)
+
+	// 创建客户端对象
+	clientObj := newWebSocketClient(conn)
+
+	// 将客户端对象添加到客户端增加的channel中
+	registerClient(clientObj)
+
+	// 启动处理数据的Goroutine
+	go handleSendData(clientObj)
+
+	// 释放client对象
+	defer func() {
+		disconnectByClient(clientObj)
+	}()
+
+	// 无限循环，每一帧对应一条完整的请求
+	for {
+		// ReadMessage方法会阻塞，所以不用考虑异步的方式
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				clientObj.WriteLog(fmt.Sprintf("读取消息时收到断开错误：%s", err))
+			} else {
+				clientObj.WriteLog(fmt.Sprintf("读取消息错误：%s", err))
+			}
+
+			break
+		}
+
+		// 记录帧类型及活跃时间
+		clientObj.receiveWebSocketMessage(messageType)
+
+		// 处理数据，如果长度为0则表示心跳包；否则处理请求内容
+		if len(message) == 0 {
+			clientObj.WriteLog("收到心跳消息")
+			continue
+		}
+
+		handleRequest(clientObj, message)
+	}
+}
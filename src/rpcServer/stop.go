@@ -0,0 +1,98 @@
+package rpcServer
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jordanzuo/ChatServerModel/src/serverResponseObject"
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+var (
+	// 是否正在停止服务器（0：否，1：是）
+	stopping int32 = 0
+
+	// 监听对象列表，及其锁对象
+	listenerList  = make([]net.Listener, 0, 2)
+	listenerMutex sync.Mutex
+)
+
+// 添加监听对象
+// listener：监听对象
+func addListener(listener net.Listener) {
+	listenerMutex.Lock()
+	defer listenerMutex.Unlock()
+
+	listenerList = append(listenerList, listener)
+}
+
+// 是否正在停止服务器
+// 返回值：
+// 是否正在停止服务器
+func isStopping() bool {
+	return atomic.LoadInt32(&stopping) == 1
+}
+
+// 停止服务器：不再接受新的连接（已有的连接不受影响）
+func StopServer() {
+	if !atomic.CompareAndSwapInt32(&stopping, 0, 1) {
+		return
+	}
+
+	listenerMutex.Lock()
+	defer listenerMutex.Unlock()
+
+	for _, listener := range listenerList {
+		if err := listener.Close(); err != nil {
+			logUtil.Log(fmt.Sprintf("关闭监听%s出错，错误信息为：%s", listener.Addr(), err), logUtil.Error, true)
+		}
+	}
+}
+
+// 向所有的客户端发送数据（包括尚未登陆的客户端）
+// responseObj：响应对象
+// priority：优先级
+func ResponseResultToAllClients(responseObj *serverResponseObject.ResponseObject, priority Priority) {
+	preparedObj := NewPreparedResponse(responseObj)
+	for _, clientObj := range getClientList() {
+		ResponseResultPrepared(clientObj, preparedObj, priority)
+	}
+}
+
+// 等待所有客户端的待发送数据发送完成
+// timeout：最长等待时间
+// 返回值：
+// 是否在超时前全部发送完成
+func WaitForSendDataDrained(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pendingCount := 0
+		for _, clientObj := range getClientList() {
+			if clientObj.getConnStatus() != con_Close && clientObj.hasPendingSendData() {
+				pendingCount++
+			}
+		}
+
+		if pendingCount == 0 {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			logUtil.Log(fmt.Sprintf("等待发送数据超时，尚有%d个客户端的数据未发送完成", pendingCount), logUtil.Warn, true)
+			return false
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 断开所有的客户端连接
+func DisconnectAllClients() {
+	for _, clientObj := range getClientList() {
+		disconnectByClient(clientObj)
+	}
+}
@@ -0,0 +1,134 @@
+package rpcServer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	"github.com/Jordanzuo/goutil/logUtil"
+)
+
+var (
+	// 证书文件路径（为空则不启用TLS）
+	tlsCertFile string
+
+	// 私钥文件路径
+	tlsKeyFile string
+
+	// 客户端CA证书文件路径（不为空则要求并验证客户端证书）
+	tlsClientCAFile string
+
+	// 最低TLS版本
+	tlsMinVersion uint16
+
+	// 当前生效的TLS配置，及其锁对象（重新加载证书时会整体替换）
+	tlsConfigObj *tls.Config
+	tlsMutex     sync.RWMutex
+)
+
+// 设置TLS配置，并加载证书
+// certFile：证书文件路径（为空则不启用TLS）
+// keyFile：私钥文件路径
+// minVersion：最低TLS版本（1.0、1.1、1.2、1.3，为空则默认为1.2）
+// clientCAFile：客户端CA证书文件路径（为空则不验证客户端证书）
+// 返回值：
+// 错误对象
+func SetTLSConfig(certFile, keyFile, minVersion, clientCAFile string) error {
+	tlsCertFile = certFile
+	tlsKeyFile = keyFile
+	tlsClientCAFile = clientCAFile
+
+	switch minVersion {
+	case "1.0":
+		tlsMinVersion = tls.VersionTLS10
+	case "1.1":
+		tlsMinVersion = tls.VersionTLS11
+	case "", "1.2":
+		tlsMinVersion = tls.VersionTLS12
+	case "1.3":
+		tlsMinVersion = tls.VersionTLS13
+	default:
+		return fmt.Errorf("TLSMinVersion配置不正确，当前的为：%s", minVersion)
+	}
+
+	return ReloadTLSCertificate()
+}
+
+// 是否启用TLS
+// 返回值：
+// 是否启用TLS
+func isTLSEnabled() bool {
+	return tlsCertFile != ""
+}
+
+// 重新加载证书（加载失败时继续使用原有的证书）
+// 返回值：
+// 错误对象
+func ReloadTLSCertificate() error {
+	if !isTLSEnabled() {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书失败，CertFile:%s，KeyFile:%s，错误信息为：%s", tlsCertFile, tlsKeyFile, err)
+	}
+
+	newTLSConfigObj := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tlsMinVersion,
+	}
+
+	if tlsClientCAFile != "" {
+		caBytes, err := ioutil.ReadFile(tlsClientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA证书失败，ClientCAFile:%s，错误信息为：%s", tlsClientCAFile, err)
+		}
+
+		clientCAPool := x509.NewCertPool()
+		if !clientCAPool.AppendCertsFromPEM(caBytes) {
+			return fmt.Errorf("解析客户端CA证书失败，ClientCAFile:%s", tlsClientCAFile)
+		}
+
+		newTLSConfigObj.ClientCAs = clientCAPool
+		newTLSConfigObj.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	tlsMutex.Lock()
+	defer tlsMutex.Unlock()
+	tlsConfigObj = newTLSConfigObj
+
+	logUtil.Log(fmt.Sprintf("加载TLS证书成功，CertFile:%s", tlsCertFile), logUtil.Info, true)
+
+	return nil
+}
+
+// 获取当前生效的TLS配置
+// 返回值：
+// TLS配置
+func getTLSConfig() *tls.Config {
+	tlsMutex.RLock()
+	defer tlsMutex.RUnlock()
+
+	return tlsConfigObj
+}
+
+// 如果启用了TLS，则将监听对象包装为TLS监听对象；否则原样返回
+// listener：原始监听对象
+// 返回值：
+// 监听对象
+func wrapTLSListener(listener net.Listener) net.Listener {
+	if !isTLSEnabled() {
+		return listener
+	}
+
+	// 每次握手时都取当前生效的配置，以便重新加载证书后无需重启监听
+	return tls.NewListener(listener, &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return getTLSConfig(), nil
+		},
+	})
+}